@@ -0,0 +1,533 @@
+package framework_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RottenNinja-Go/framework"
+	"github.com/RottenNinja-Go/framework/handler"
+)
+
+type CreateNoteRequest struct {
+	Body struct {
+		Text string `json:"text" validate:"required"`
+	}
+}
+
+type Note struct {
+	Text string `json:"text"`
+}
+
+// newNoteApp returns an app echoing notes posted to /notes
+func newNoteApp() *framework.Framework {
+	app := framework.New()
+	handler.POST(app, "/notes", func(ctx context.Context, req CreateNoteRequest) (Note, error) {
+		return Note{Text: req.Body.Text}, nil
+	}, func(eo handler.EndpointOptions) {})
+	return app
+}
+
+func TestOversizedBodyIs413(t *testing.T) {
+	app := newNoteApp()
+	app.SetMaxRequestSize(64)
+
+	r := jsonRequest(http.MethodPost, "/notes", `{"text":"`+strings.Repeat("x", 1024)+`"}`)
+	// Without a declared length the limit is hit while decoding
+	r.ContentLength = -1
+
+	w := serve(app, r)
+	expectStatus(t, w, http.StatusRequestEntityTooLarge)
+	if !strings.Contains(w.Body.String(), "request body too large") {
+		t.Errorf("body = %s, want a request body too large error", w.Body.String())
+	}
+}
+
+type ChecksumRequest struct {
+	Body struct {
+		Text string `json:"text"`
+	}
+	Trailer struct {
+		Checksum string `json:"X-Checksum" validate:"required"`
+	}
+}
+
+type ChecksumResponse struct {
+	Text     string `json:"text"`
+	Checksum string `json:"checksum"`
+}
+
+func TestTrailerBindsAfterBody(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/notes", func(ctx context.Context, req ChecksumRequest) (ChecksumResponse, error) {
+		return ChecksumResponse{Text: req.Body.Text, Checksum: req.Trailer.Checksum}, nil
+	}, func(eo handler.EndpointOptions) {})
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	// The trailer is only set once the whole body has been written
+	body, bodyWriter := io.Pipe()
+	r, err := http.NewRequest(http.MethodPost, server.URL+"/notes", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Trailer = http.Header{"X-Checksum": nil}
+	go func() {
+		io.WriteString(bodyWriter, `{"text":"hello"}`)
+		r.Trailer.Set("X-Checksum", "abc123")
+		bodyWriter.Close()
+	}()
+
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var got ChecksumResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || got.Text != "hello" || got.Checksum != "abc123" {
+		t.Errorf("got %d %+v, want 200 with text hello and checksum abc123", resp.StatusCode, got)
+	}
+}
+
+func TestValidationStatus(t *testing.T) {
+	app := newNoteApp()
+	expectStatus(t, serve(app, jsonRequest(http.MethodPost, "/notes", `{}`)), http.StatusBadRequest)
+
+	app.SetValidationStatus(http.StatusUnprocessableEntity)
+	expectStatus(t, serve(app, jsonRequest(http.MethodPost, "/notes", `{}`)), http.StatusUnprocessableEntity)
+	// Malformed JSON is still a bad request
+	expectStatus(t, serve(app, jsonRequest(http.MethodPost, "/notes", `{"text":`)), http.StatusBadRequest)
+}
+
+func TestSkipValidation(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/notes", func(ctx context.Context, req CreateNoteRequest) (Note, error) {
+		return Note{Text: req.Body.Text}, nil
+	}, func(eo handler.EndpointOptions) {
+		eo.SetSkipValidation(true)
+	})
+
+	// text is required, but the endpoint doesn't validate
+	w := serve(app, jsonRequest(http.MethodPost, "/notes", `{"text":""}`))
+	expectStatus(t, w, http.StatusOK)
+
+	// Fields are still bound
+	w = serve(app, jsonRequest(http.MethodPost, "/notes", `{"text":"hi"}`))
+	expectStatus(t, w, http.StatusOK)
+	if body := w.Body.String(); body != `{"text":"hi"}`+"\n" {
+		t.Errorf("body = %s, want the bound text", body)
+	}
+}
+
+// signature returns the hex HMAC-SHA256 of body
+func signature(body string) string {
+	mac := hmac.New(sha256.New, []byte("webhook-secret"))
+	io.WriteString(mac, body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestPreParseHookRejectsUnsignedRequests(t *testing.T) {
+	app := newNoteApp()
+	app.SetPreParseHook(func(r *http.Request) error {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if !hmac.Equal([]byte(r.Header.Get("X-Signature")), []byte(signature(string(body)))) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	})
+
+	w := serve(app, jsonRequest(http.MethodPost, "/notes", `{"text":"hi"}`))
+	expectStatus(t, w, http.StatusBadRequest)
+	if !strings.Contains(w.Body.String(), "invalid signature") {
+		t.Errorf("body = %s, want the hook's error", w.Body.String())
+	}
+
+	r := jsonRequest(http.MethodPost, "/notes", `{"text":"hi"}`)
+	r.Header.Set("X-Signature", signature(`{"text":"hi"}`))
+	w = serve(app, r)
+	expectStatus(t, w, http.StatusOK)
+	if body := w.Body.String(); body != `{"text":"hi"}`+"\n" {
+		t.Errorf("body = %s, want the note parsed after the hook", body)
+	}
+}
+
+type ProfileRequest struct {
+	Body struct {
+		Age    int     `json:"age" validate:"min=18"`
+		Active bool    `json:"active"`
+		Score  float64 `json:"score"`
+	}
+}
+
+type Profile struct {
+	Age    int     `json:"age"`
+	Active bool    `json:"active"`
+	Score  float64 `json:"score"`
+}
+
+func TestLenientBody(t *testing.T) {
+	const body = `{"age":"30","active":"true","score":"1.5"}`
+	newApp := func(lenient bool) *framework.Framework {
+		app := framework.New()
+		app.SetLenientBody(lenient)
+		handler.POST(app, "/profiles", func(ctx context.Context, req ProfileRequest) (Profile, error) {
+			return Profile(req.Body), nil
+		}, func(eo handler.EndpointOptions) {})
+		return app
+	}
+
+	// Strict is the default
+	expectStatus(t, serve(newApp(false), jsonRequest(http.MethodPost, "/profiles", body)), http.StatusBadRequest)
+
+	w := serve(newApp(true), jsonRequest(http.MethodPost, "/profiles", body))
+	expectStatus(t, w, http.StatusOK)
+	if got := w.Body.String(); got != `{"age":30,"active":true,"score":1.5}`+"\n" {
+		t.Errorf("body = %s, want the coerced values", got)
+	}
+
+	// Coerced values are still validated
+	w = serve(newApp(true), jsonRequest(http.MethodPost, "/profiles", `{"age":"12"}`))
+	expectStatus(t, w, http.StatusBadRequest)
+}
+
+type AdultRequest struct {
+	Body struct {
+		Name string `json:"name" validate:"required"`
+		Age  int    `json:"age" validate:"min=18" errmsg:"must be an adult"`
+	}
+}
+
+func TestErrmsgTag(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/adults", func(ctx context.Context, req AdultRequest) (Note, error) {
+		return Note{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	w := serve(app, jsonRequest(http.MethodPost, "/adults", `{"age":12}`))
+	expectStatus(t, w, http.StatusBadRequest)
+
+	var resp framework.ValidationErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	messages := map[string][]string{}
+	for _, field := range resp.Fields {
+		messages[field.Field] = field.Errors
+	}
+	if got := messages["Age"]; len(got) != 1 || got[0] != "must be an adult" {
+		t.Errorf("age errors = %q, want the errmsg tag", got)
+	}
+	// Fields without the tag keep the generated message
+	if got := messages["Name"]; len(got) != 1 || got[0] == "" || got[0] == "must be an adult" {
+		t.Errorf("name errors = %q, want the default message", got)
+	}
+}
+
+type ProxyRequest struct {
+	Header struct {
+		ContentType string `json:"Content-Type"`
+	}
+	Body io.Reader
+}
+
+type ProxyResponse struct {
+	Status   int    `json:"status"`
+	Upstream string `json:"upstream"`
+}
+
+func TestStreamedBodyProxy(t *testing.T) {
+	var received string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = r.Header.Get("Content-Type") + " " + string(body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer upstream.Close()
+
+	app := framework.New()
+	handler.POST(app, "/upload", func(ctx context.Context, req ProxyRequest) (ProxyResponse, error) {
+		resp, err := http.Post(upstream.URL, req.Header.ContentType, req.Body)
+		if err != nil {
+			return ProxyResponse{}, err
+		}
+		resp.Body.Close()
+		return ProxyResponse{Status: resp.StatusCode, Upstream: received}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	r := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("raw,csv,data"))
+	r.Header.Set("Content-Type", "text/csv")
+	w := serve(app, r)
+	expectStatus(t, w, http.StatusOK)
+	if got := w.Body.String(); got != `{"status":201,"upstream":"text/csv raw,csv,data"}`+"\n" {
+		t.Errorf("body = %s, want the upstream to receive the raw body", got)
+	}
+}
+
+type StreamedChecksumRequest struct {
+	Body    io.Reader
+	Trailer struct {
+		Checksum string `json:"X-Checksum"`
+	}
+}
+
+func TestTrailerWithStreamedBodyRejected(t *testing.T) {
+	app := framework.New()
+	message := registrationPanic(func() {
+		handler.POST(app, "/upload", func(ctx context.Context, req StreamedChecksumRequest) (Note, error) {
+			return Note{}, nil
+		}, func(eo handler.EndpointOptions) {})
+	})
+	if !strings.Contains(message, "Trailer") || !strings.Contains(message, "io.Reader") {
+		t.Errorf("panic = %q, want Trailer with a streamed body rejected", message)
+	}
+}
+
+func TestPostParseHookTrims(t *testing.T) {
+	app := newNoteApp()
+	app.SetPostParseHook(func(ctx context.Context, req any) error {
+		if note, ok := req.(*CreateNoteRequest); ok {
+			note.Body.Text = strings.TrimSpace(note.Body.Text)
+		}
+		return nil
+	})
+
+	w := serve(app, jsonRequest(http.MethodPost, "/notes", `{"text":"  hello \n"}`))
+	expectStatus(t, w, http.StatusOK)
+	if got := w.Body.String(); got != `{"text":"hello"}`+"\n" {
+		t.Errorf("body = %s, want the trimmed text", got)
+	}
+
+	// The hook runs before validation, so blank text fails required
+	expectStatus(t, serve(app, jsonRequest(http.MethodPost, "/notes", `{"text":"   "}`)), http.StatusBadRequest)
+}
+
+func TestBOMPrefixedBody(t *testing.T) {
+	app := newNoteApp()
+
+	w := serve(app, jsonRequest(http.MethodPost, "/notes", "\xef\xbb\xbf"+`{"text":"hi"}`))
+	expectStatus(t, w, http.StatusOK)
+	if got := w.Body.String(); got != `{"text":"hi"}`+"\n" {
+		t.Errorf("body = %s, want the note", got)
+	}
+
+	// Only a leading BOM is skipped
+	expectStatus(t, serve(app, jsonRequest(http.MethodPost, "/notes", " \xef\xbb\xbf"+`{"text":"hi"}`)), http.StatusBadRequest)
+}
+
+type LineItem struct {
+	SKU      string `json:"sku" validate:"required"`
+	Quantity int    `json:"quantity" default:"1" validate:"min=1"`
+}
+
+type CreateOrderRequest struct {
+	Body struct {
+		Currency string     `json:"currency" default:"EUR"`
+		Items    []LineItem `json:"items"`
+	}
+}
+
+type Order struct {
+	Currency string     `json:"currency"`
+	Items    []LineItem `json:"items"`
+}
+
+func TestBodyDefaults(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/orders", func(ctx context.Context, req CreateOrderRequest) (Order, error) {
+		return Order(req.Body), nil
+	}, func(eo handler.EndpointOptions) {})
+
+	w := serve(app, jsonRequest(http.MethodPost, "/orders", `{"items":[{"sku":"a"},{"sku":"b","quantity":3}]}`))
+	expectStatus(t, w, http.StatusOK)
+	want := `{"currency":"EUR","items":[{"sku":"a","quantity":1},{"sku":"b","quantity":3}]}`
+	if got := w.Body.String(); got != want+"\n" {
+		t.Errorf("body = %s, want %s", got, want)
+	}
+}
+
+type BadDefaultItem struct {
+	Quantity int `json:"quantity" default:"many"`
+}
+
+type BadDefaultRequest struct {
+	Body struct {
+		Items []BadDefaultItem `json:"items"`
+	}
+}
+
+func TestBodyDefaultsCheckedAtRegistration(t *testing.T) {
+	app := framework.New()
+	message := registrationPanic(func() {
+		handler.POST(app, "/orders", func(ctx context.Context, req BadDefaultRequest) (Note, error) {
+			return Note{}, nil
+		}, func(eo handler.EndpointOptions) {})
+	})
+	if !strings.Contains(message, "framework: body:") || !strings.Contains(message, "Quantity") {
+		t.Errorf("panic = %q, want the bad default reported", message)
+	}
+}
+
+type AgeRequest struct {
+	Query struct {
+		MinAge *int `json:"min_age" validate:"required,min=0"`
+	}
+	Body struct {
+		Age *int `json:"age" validate:"required,min=0"`
+	}
+}
+
+type AgeResponse struct {
+	MinAge int `json:"min_age"`
+	Age    int `json:"age"`
+}
+
+func TestRequiredPointerAcceptsZero(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/ages", func(ctx context.Context, req AgeRequest) (AgeResponse, error) {
+		return AgeResponse{MinAge: *req.Query.MinAge, Age: *req.Body.Age}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	w := serve(app, jsonRequest(http.MethodPost, "/ages?min_age=0", `{"age":0}`))
+	expectStatus(t, w, http.StatusOK)
+	if got := w.Body.String(); got != `{"min_age":0,"age":0}`+"\n" {
+		t.Errorf("body = %s, want the explicit zeros", got)
+	}
+
+	expectStatus(t, serve(app, jsonRequest(http.MethodPost, "/ages?min_age=0", `{}`)), http.StatusBadRequest)
+	expectStatus(t, serve(app, jsonRequest(http.MethodPost, "/ages", `{"age":0}`)), http.StatusBadRequest)
+}
+
+func TestDeclaredContentLengthRejectedEarly(t *testing.T) {
+	app := newNoteApp()
+	app.SetMaxRequestSize(64)
+
+	r := jsonRequest(http.MethodPost, "/notes", `{"text":"`+strings.Repeat("x", 1024)+`"}`)
+	body := &countingReader{r: r.Body}
+	r.Body = io.NopCloser(body)
+
+	w := serve(app, r)
+	expectStatus(t, w, http.StatusRequestEntityTooLarge)
+	if body.n != 0 {
+		t.Errorf("read %d body bytes, want the request rejected before reading", body.n)
+	}
+}
+
+// stubEngine is a Validator that reports a fixed error for every request
+type stubEngine struct {
+	err   error
+	calls int
+}
+
+func (v *stubEngine) Validate(req any) error {
+	v.calls++
+	return v.err
+}
+
+// stubErrors is a structured error as returned by adapters for other engines
+type stubErrors []framework.ValidationError
+
+func (e stubErrors) Error() string {
+	return "stub validation failed"
+}
+
+func (e stubErrors) ValidationErrors() []framework.ValidationError {
+	return e
+}
+
+func TestValidatorEngine(t *testing.T) {
+	app := newNoteApp()
+	engine := &stubEngine{err: stubErrors{{Field: "text", SourceType: "body", Errors: []string{"must be polite"}}}}
+	app.SetValidatorEngine(engine)
+	app.SetValidationStatus(http.StatusUnprocessableEntity)
+
+	w := serve(app, jsonRequest(http.MethodPost, "/notes", `{"text":"hello"}`))
+	expectStatus(t, w, http.StatusUnprocessableEntity)
+	var resp framework.ValidationErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Fields) != 1 || resp.Fields[0].Field != "text" || resp.Fields[0].Errors[0] != "must be polite" {
+		t.Errorf("fields = %+v, want the stub engine's error", resp.Fields)
+	}
+	if engine.calls != 1 {
+		t.Errorf("engine called %d times, want 1", engine.calls)
+	}
+
+	// Unstructured errors are reported with their message
+	engine.err = errors.New("text is rude")
+	w = serve(app, jsonRequest(http.MethodPost, "/notes", `{"text":"hello"}`))
+	expectStatus(t, w, http.StatusBadRequest)
+	if !strings.Contains(w.Body.String(), "text is rude") {
+		t.Errorf("body = %s, want the engine's message", w.Body.String())
+	}
+
+	// The stub replaces the default validator, so required tags aren't checked
+	engine.err = nil
+	expectStatus(t, serve(app, jsonRequest(http.MethodPost, "/notes", `{}`)), http.StatusOK)
+
+	// A nil engine restores the default
+	app.SetValidatorEngine(nil)
+	expectStatus(t, serve(app, jsonRequest(http.MethodPost, "/notes", `{}`)), http.StatusUnprocessableEntity)
+}
+
+type CreatePostRequest struct {
+	Body struct {
+		Title string `json:"title" validate:"required"`
+	}
+	Form struct {
+		Title string `json:"title" validate:"required"`
+	}
+}
+
+func TestConsumesDispatch(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/posts", func(ctx context.Context, req CreatePostRequest) (Note, error) {
+		return Note{Text: req.Body.Title + req.Form.Title}, nil
+	}, func(eo handler.EndpointOptions) {
+		eo.SetConsumes("application/json", "application/x-www-form-urlencoded")
+	})
+
+	// Each content type binds its own struct, and only that one is validated
+	w := serve(app, jsonRequest(http.MethodPost, "/posts", `{"title":"from json"}`))
+	expectStatus(t, w, http.StatusOK)
+	if !strings.Contains(w.Body.String(), "from json") {
+		t.Errorf("body = %s, want the JSON title", w.Body.String())
+	}
+
+	form := func(body string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return r
+	}
+	w = serve(app, form("title=from+form"))
+	expectStatus(t, w, http.StatusOK)
+	if !strings.Contains(w.Body.String(), "from form") {
+		t.Errorf("body = %s, want the form title", w.Body.String())
+	}
+
+	// Required fields of the bound struct are still enforced
+	expectStatus(t, serve(app, jsonRequest(http.MethodPost, "/posts", `{}`)), http.StatusBadRequest)
+	expectStatus(t, serve(app, form("")), http.StatusBadRequest)
+
+	r := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader("title"))
+	r.Header.Set("Content-Type", "text/plain")
+	expectStatus(t, serve(app, r), http.StatusUnsupportedMediaType)
+}
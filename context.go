@@ -0,0 +1,62 @@
+package framework
+
+import (
+	"context"
+	"sync"
+)
+
+// claimsContextKey is the context key used to store authentication claims
+type claimsContextKey struct{}
+
+// WithClaims returns a copy of ctx carrying the given authentication claims
+// Authentication middleware uses this to expose parsed token claims to handlers
+func WithClaims(ctx context.Context, claims any) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext returns the authentication claims stored in ctx, if any
+// Example: claims, ok := framework.ClaimsFromContext(ctx)
+func ClaimsFromContext(ctx context.Context) (any, bool) {
+	claims := ctx.Value(claimsContextKey{})
+	return claims, claims != nil
+}
+
+// valuesContextKey is the context key used to store request-scoped values
+type valuesContextKey struct{}
+
+// contextValues is the request-scoped value store shared through the context
+type contextValues struct {
+	mu     sync.RWMutex
+	values map[string]any
+}
+
+// Set stores value under key in the request-scoped value store and returns the context carrying it
+// The store is created on first use; later calls on derived contexts share the same store,
+// so a value set by a handler is visible to the middleware that wrapped it
+// Example: ctx = framework.Set(ctx, "tenant", tenantID)
+func Set(ctx context.Context, key string, value any) context.Context {
+	store, ok := ctx.Value(valuesContextKey{}).(*contextValues)
+	if !ok {
+		store = &contextValues{values: make(map[string]any)}
+		ctx = context.WithValue(ctx, valuesContextKey{}, store)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.values[key] = value
+	return ctx
+}
+
+// Get returns the value stored under key by Set, if any
+// Example: tenant, ok := framework.Get(ctx, "tenant")
+func Get(ctx context.Context, key string) (any, bool) {
+	store, ok := ctx.Value(valuesContextKey{}).(*contextValues)
+	if !ok {
+		return nil, false
+	}
+
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	value, ok := store.values[key]
+	return value, ok
+}
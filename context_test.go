@@ -0,0 +1,41 @@
+package framework_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RottenNinja-Go/framework"
+	"github.com/RottenNinja-Go/framework/handler"
+)
+
+type TenantResponse struct {
+	Tenant string `json:"tenant"`
+}
+
+func TestContextValues(t *testing.T) {
+	app := framework.New()
+	app.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := framework.Set(r.Context(), "tenant", r.Header.Get("X-Tenant"))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+	handler.GET(app, "/tenant", func(ctx context.Context, _ framework.NoRequest) (TenantResponse, error) {
+		tenant, _ := framework.Get(ctx, "tenant")
+		return TenantResponse{Tenant: tenant.(string)}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	r := httptest.NewRequest(http.MethodGet, "/tenant", nil)
+	r.Header.Set("X-Tenant", "acme")
+	w := serve(app, r)
+	expectStatus(t, w, http.StatusOK)
+	if got := w.Body.String(); got != `{"tenant":"acme"}`+"\n" {
+		t.Errorf("body = %s, want the tenant set by the middleware", got)
+	}
+
+	if _, ok := framework.Get(context.Background(), "tenant"); ok {
+		t.Error("Get() found a value in an empty context")
+	}
+}
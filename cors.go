@@ -0,0 +1,99 @@
+package framework
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures the CORS middleware
+type CORSOptions struct {
+	// AllowedOrigins lists the origins allowed to make cross-origin requests
+	// "*" allows any origin
+	AllowedOrigins []string
+
+	// AllowedMethods lists the methods allowed in preflight requests
+	// Empty allows GET, HEAD, POST, PUT, PATCH and DELETE
+	AllowedMethods []string
+
+	// AllowedHeaders lists the request headers allowed in preflight requests
+	// Empty allows whatever headers the preflight asks for
+	AllowedHeaders []string
+
+	// ExposedHeaders lists the response headers browsers may read
+	ExposedHeaders []string
+
+	// AllowCredentials lets browsers send cookies and authorization headers
+	// The request's origin is echoed back instead of "*", as browsers require
+	AllowCredentials bool
+
+	// MaxAge is how long browsers may cache a preflight response, zero leaves it to the browser
+	MaxAge time.Duration
+}
+
+// CORS sets the Access-Control-Allow-* headers for requests from allowed origins and
+// answers preflight requests with 204 No Content without calling the handler
+// Requests from other origins get no CORS headers, so browsers block them
+// Preflight requests to paths without an OPTIONS endpoint pass through the middlewares
+// of the group that registered the path, so it can be scoped with Group.Use
+func CORS(opts CORSOptions) Middleware {
+	anyOrigin := slices.Contains(opts.AllowedOrigins, "*")
+	methods := opts.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{
+			http.MethodGet, http.MethodHead, http.MethodPost,
+			http.MethodPut, http.MethodPatch, http.MethodDelete,
+		}
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(opts.AllowedHeaders, ", ")
+	exposeHeaders := strings.Join(opts.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(opts.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			origin := r.Header.Get("Origin")
+			if !anyOrigin || opts.AllowCredentials {
+				header.Add("Vary", "Origin")
+			}
+			if origin == "" || (!anyOrigin && !slices.Contains(opts.AllowedOrigins, origin)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if anyOrigin && !opts.AllowCredentials {
+				header.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				header.Set("Access-Control-Allow-Origin", origin)
+			}
+			if opts.AllowCredentials {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if !preflight {
+				if exposeHeaders != "" {
+					header.Set("Access-Control-Expose-Headers", exposeHeaders)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			header.Set("Access-Control-Allow-Methods", allowMethods)
+			if allowHeaders != "" {
+				header.Set("Access-Control-Allow-Headers", allowHeaders)
+			} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+				header.Set("Access-Control-Allow-Headers", requested)
+			}
+			if opts.MaxAge > 0 {
+				header.Set("Access-Control-Max-Age", maxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package framework
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// applyDefaults sets fields tagged with `default:"..."` that are still at their zero value,
+// descending into nested structs, non-nil struct pointers and slices of structs
+// A zero value can't be told apart from an omitted field, so explicit zeros (false, 0, "")
+// are replaced by the default as well
+func applyDefaults(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return applyDefaults(v.Elem())
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := applyDefaults(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+	default:
+		return nil
+	}
+
+	structType := v.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := v.Field(i)
+
+		defaultValue, ok := field.Tag.Lookup("default")
+		if !ok || !fieldValue.IsZero() {
+			if err := applyDefaults(fieldValue); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := setDefault(fieldValue, defaultValue); err != nil {
+			return fmt.Errorf("default for '%s': %w", field.Name, err)
+		}
+	}
+	return nil
+}
+
+// checkDefaults verifies every default tag in t, including those of nested structs,
+// can be set on its field, so bad defaults fail at registration instead of per request
+func checkDefaults(t reflect.Type) error {
+	return checkDefaultsIn(t, "", make(map[reflect.Type]bool))
+}
+
+// checkDefaultsIn checks the default tags of t, naming fields below prefix
+func checkDefaultsIn(t reflect.Type, prefix string, seen map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return nil
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if defaultValue, ok := field.Tag.Lookup("default"); ok {
+			if err := setDefault(reflect.New(field.Type).Elem(), defaultValue); err != nil {
+				return fmt.Errorf("invalid default %q for '%s%s': %w", defaultValue, prefix, field.Name, err)
+			}
+			continue
+		}
+		if err := checkDefaultsIn(field.Type, prefix+field.Name+".", seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setDefault sets fieldValue from a default tag value
+// Slices take comma-separated values
+func setDefault(fieldValue reflect.Value, defaultValue string) error {
+	if fieldValue.Kind() == reflect.Slice {
+		values := strings.Split(defaultValue, ",")
+		setter := createFieldSetter(fieldValue.Type().Elem().Kind())
+		slice := reflect.MakeSlice(fieldValue.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setter(slice.Index(i), strings.TrimSpace(value)); err != nil {
+				return err
+			}
+		}
+		fieldValue.Set(slice)
+		return nil
+	}
+
+	return createFieldSetter(fieldValue.Kind())(fieldValue, defaultValue)
+}
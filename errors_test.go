@@ -0,0 +1,34 @@
+package framework_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RottenNinja-Go/framework"
+	"github.com/RottenNinja-Go/framework/handler"
+)
+
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+func TestMapError(t *testing.T) {
+	app := framework.New()
+	app.MapError(ErrQuotaExceeded, http.StatusTooManyRequests, "slow down")
+	handler.GET(app, "/quota", func(ctx context.Context, _ framework.NoRequest) (UploadResponse, error) {
+		return UploadResponse{}, fmt.Errorf("user 42: %w", ErrQuotaExceeded)
+	}, func(eo handler.EndpointOptions) {})
+	handler.GET(app, "/other", func(ctx context.Context, _ framework.NoRequest) (UploadResponse, error) {
+		return UploadResponse{}, errors.New("disk full")
+	}, func(eo handler.EndpointOptions) {})
+
+	w := serve(app, httptest.NewRequest(http.MethodGet, "/quota", nil))
+	expectStatus(t, w, http.StatusTooManyRequests)
+	if got := w.Body.String(); got != `{"error":"slow down"}`+"\n" {
+		t.Errorf("body = %s, want the mapped message", got)
+	}
+
+	expectStatus(t, serve(app, httptest.NewRequest(http.MethodGet, "/other", nil)), http.StatusInternalServerError)
+}
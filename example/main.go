@@ -100,8 +100,8 @@ type ListUsersRequest struct {
 		APIKey string `json:"X-API-Key" validate:"required" doc:"API authentication key"`
 	}
 	Query struct {
-		Page     int      `json:"page" validate:"omitempty,min=1" doc:"Page number (default: 1)"`
-		PageSize int      `json:"page_size" validate:"omitempty,min=1,max=100" doc:"Items per page (default: 10, max: 100)"`
+		Page     int      `json:"page" default:"1" validate:"min=1" doc:"Page number"`
+		PageSize int      `json:"page_size" default:"10" validate:"min=1,max=100" doc:"Items per page (max: 100)"`
 		SortBy   string   `json:"sort_by" validate:"omitempty,oneof=name email age created_at" doc:"Field to sort by"`
 		Order    string   `json:"order" validate:"omitempty,oneof=asc desc" doc:"Sort order (asc or desc)"`
 		Tags     []string `json:"tags" doc:"Filter by tags (can specify multiple: ?tags=admin&tags=premium)"`
@@ -339,16 +339,8 @@ func ListUsers(ctx context.Context, req ListUsersRequest) (ListUsersResponse, er
 		return ListUsersResponse{}, fmt.Errorf("invalid API key")
 	}
 
-	// Set defaults
 	page := req.Query.Page
-	if page == 0 {
-		page = 1
-	}
-
 	pageSize := req.Query.PageSize
-	if pageSize == 0 {
-		pageSize = 10
-	}
 
 	// Convert map to slice
 	userList := make([]User, 0, len(users))
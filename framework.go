@@ -1,29 +1,77 @@
 package framework
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"mime/multipart"
 	"net/http"
+	"net/url"
+	"os"
 	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 )
 
 // Framework is the main API framework
 type Framework struct {
-	mux       *http.ServeMux
-	validator *validator.Validate
-	endpoints []*EndpointSpec
+	mux         Mux
+	validator   Validator
+	endpoints   []*EndpointSpec
+	middlewares []Middleware
+
+	maxMultipartFiles  int
+	maxMultipartMemory int64
+	maxRequestSize     int64
+	envelope           func(data any) any
+	validationStatus   int
+	problemDetails     bool
+	preParseHook       func(r *http.Request) error
+	postParseHook      func(ctx context.Context, req any) error
+	plugins            []Plugin
+	lenientBody        bool
+	bodyDecoders       map[string]BodyDecoder
+	indentPrefix       string
+	indent             string
+
+	clientTimeoutHeader string        // header carrying a client-requested timeout, if enabled
+	maxClientTimeout    time.Duration // upper bound for client-requested timeouts
+
+	errorMappings      []errorMapping
+	strictAccept       bool
+	strictRegistration bool
+
+	autoOptions     bool
+	notFoundHandler http.Handler
+	routeMethods    map[string][]string     // methods registered per path, for Allow
+	optionsHandlers map[string]http.Handler // explicit OPTIONS endpoints per path
+
+	// 405 responses to OPTIONS requests per path, wrapped in the path's middlewares
+	optionsFallbacks map[string]http.Handler
 }
 
 // Group represents a group of routes with a common path prefix and middleware
 type Group struct {
 	framework   *Framework
 	prefix      string
-	middlewares []Middleware
+	middlewares []groupMiddleware
+}
+
+// groupMiddleware is a group middleware, optionally limited to some HTTP methods
+type groupMiddleware struct {
+	middleware Middleware
+	methods    []string // nil applies to every method
 }
 
 type StatusResponse[Resp any] struct {
@@ -31,12 +79,19 @@ type StatusResponse[Resp any] struct {
 	Body Resp
 }
 
+// Mux is the minimal router backend the framework registers endpoints on
+// *http.ServeMux implements it
+type Mux interface {
+	Handle(pattern string, handler http.Handler)
+	ServeHTTP(w http.ResponseWriter, r *http.Request)
+}
+
 // Router is an interface that both Framework and Group implement
 // This allows the same GET, POST, PUT, PATCH, DELETE functions to work with both
 type Router interface {
 	getFramework() *Framework
 	getPrefix() string
-	getMiddlewares() []Middleware
+	getMiddlewares(method string) []Middleware
 }
 
 type NoRequest struct{}
@@ -58,6 +113,51 @@ type FileField struct {
 // isFileUpload implements the FileUpload interface
 func (FileField) isFileUpload() {}
 
+// Bytes reads the entire file content and closes it
+func (ff FileField) Bytes() ([]byte, error) {
+	if ff.Content == nil {
+		return nil, fmt.Errorf("file has no content")
+	}
+	defer ff.Content.Close()
+
+	return io.ReadAll(ff.Content)
+}
+
+// Save writes the file content to the given path and closes it
+func (ff FileField) Save(path string) error {
+	if ff.Content == nil {
+		return fmt.Errorf("file has no content")
+	}
+	defer ff.Content.Close()
+
+	dst, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+
+	if _, err := io.Copy(dst, ff.Content); err != nil {
+		dst.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return dst.Close()
+}
+
+// onceCloser wraps an io.ReadCloser so that closing it more than once is safe
+type onceCloser struct {
+	io.ReadCloser
+	once sync.Once
+	err  error
+}
+
+// Close closes the underlying reader on the first call and returns its result on every call
+func (c *onceCloser) Close() error {
+	c.once.Do(func() {
+		c.err = c.ReadCloser.Close()
+	})
+	return c.err
+}
+
 // Handler is a type-safe handler function that takes a request and returns a response
 type Handler[Req any, Resp any] func(ctx context.Context, req Req) (Resp, error)
 
@@ -65,18 +165,47 @@ type Handler[Req any, Resp any] func(ctx context.Context, req Req) (Resp, error)
 // It receives the next handler and returns a new handler that can wrap it
 type Middleware func(next http.Handler) http.Handler
 
-//	type HandlerRoute[TReq any, TResp any] struct {
-//		*EndpointSpec
-//		Handler func(ctx context.Context, req TReq) (TResp, error)
-//	}
+// When returns a middleware that applies mw only to requests matching pred
+// Requests that don't match skip mw and go straight to the next handler
+// Example: Use(When(func(r *http.Request) bool { return !strings.HasPrefix(r.URL.Path, "/public/") }, auth))
+func When(pred func(*http.Request) bool, mw Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		wrapped := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if pred(r) {
+				wrapped.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Endpoint is an endpoint created by CreateEndpoint, configured through its setters
+// and registered on a Framework or Group with RegisterEndpoint
 type Endpoint interface {
 	SetSummary(summary string)
 	SetDescription(description string)
 	Use(middleware ...Middleware)
 	SetTags(tags ...string)
+	SetDisableEnvelope(disable bool)
+	SetSkipValidation(skip bool)
+	SetProduces(contentType string)
+	SetConsumes(contentTypes ...string)
+	SetHidden(hidden bool)
+	SetResponseHeaders(code int, headers map[string]HeaderSpec)
+	SetTimeout(timeout time.Duration)
+	SetSuccessStatus(code int)
 	getSpec() *EndpointSpec
 }
 
+// HeaderSpec documents a response header in the OpenAPI spec
+type HeaderSpec struct {
+	Description string
+	// Type is the JSON schema type of the header value (defaults to "string")
+	Type string
+}
+
 // EndpointSpec defines the specification for an endpoint
 type EndpointSpec struct {
 	Method       string
@@ -89,7 +218,25 @@ type EndpointSpec struct {
 	ResponseType reflect.Type
 	Middlewares  []Middleware
 
+	// DisableEnvelope skips the framework's response envelope for this endpoint
+	DisableEnvelope bool
+	// SkipValidation binds the request without running the struct validator
+	SkipValidation bool
+	// Produces is the content type of successful responses (defaults to application/json)
+	Produces string
+	// Consumes lists the accepted request content types; other types get 415 Unsupported Media Type
+	Consumes []string
+	// Hidden keeps the endpoint out of the generated OpenAPI spec
+	Hidden bool
+	// ResponseHeaders documents the headers sent with each response status code
+	ResponseHeaders map[int]map[string]HeaderSpec
+	// Timeout bounds the handler's context; exceeding it responds with 503 Service Unavailable
+	Timeout time.Duration
+	// SuccessStatus is the status code of successful responses (defaults to 200)
+	SuccessStatus int
+
 	AllMiddlewares []Middleware
+	parser         *requestParser
 	handlerPrepFn  func(*Framework) http.HandlerFunc
 	// handlerFunc  http.HandlerFunc
 }
@@ -109,6 +256,92 @@ func (b *EndpointSpec) SetTags(tags ...string) {
 	b.Tags = tags
 }
 
+// SetDisableEnvelope disables the framework's response envelope for this endpoint
+func (b *EndpointSpec) SetDisableEnvelope(disable bool) {
+	b.DisableEnvelope = disable
+}
+
+// SetSkipValidation disables struct validation for this endpoint
+// Fields are still bound, which suits passthrough endpoints that validate on their own
+func (b *EndpointSpec) SetSkipValidation(skip bool) {
+	b.SkipValidation = skip
+}
+
+// SetProduces sets the content type of successful responses
+func (b *EndpointSpec) SetProduces(contentType string) {
+	b.Produces = contentType
+}
+
+// SetConsumes sets the request content types the endpoint accepts, e.g. JSON and multipart
+// Requests with a body of another type are rejected with 415 Unsupported Media Type.
+// A request type with both Body and Form binds form bodies to Form and any other body to
+// Body, decoded as JSON or with the decoder registered for its type (RegisterBodyDecoder)
+func (b *EndpointSpec) SetConsumes(contentTypes ...string) {
+	b.Consumes = contentTypes
+}
+
+// consumesRequest reports whether the endpoint accepts the content type of r's body
+// Requests without a body are always accepted
+func (b *EndpointSpec) consumesRequest(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	if len(b.Consumes) == 0 || (contentType == "" && r.ContentLength == 0) {
+		return true
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	for _, consumed := range b.Consumes {
+		consumedType, _, _ := mime.ParseMediaType(consumed)
+		if strings.EqualFold(mediaType, consumedType) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetHidden hides the endpoint from the generated OpenAPI spec
+// The route is still served, it's just undocumented
+func (b *EndpointSpec) SetHidden(hidden bool) {
+	b.Hidden = hidden
+}
+
+// SetResponseHeaders documents the headers sent with responses of the given status code
+// Example: SetResponseHeaders(200, map[string]HeaderSpec{"X-Rate-Limit-Remaining": {Type: "integer"}})
+func (b *EndpointSpec) SetResponseHeaders(code int, headers map[string]HeaderSpec) {
+	if b.ResponseHeaders == nil {
+		b.ResponseHeaders = make(map[int]map[string]HeaderSpec)
+	}
+	b.ResponseHeaders[code] = headers
+}
+
+// SetTimeout sets a deadline on the handler's context
+// A handler still running when it expires gets a 503 Service Unavailable response, even
+// if it then returns successfully, unless it already started writing the response
+func (b *EndpointSpec) SetTimeout(timeout time.Duration) {
+	b.Timeout = timeout
+}
+
+// SetSuccessStatus sets the status code of successful responses, e.g. 201 for create endpoints
+// Responses implementing StatusCoder or returned as StatusResponse still choose their own code
+func (b *EndpointSpec) SetSuccessStatus(code int) {
+	b.SuccessStatus = code
+}
+
+// produces returns the content type of the endpoint's successful responses
+func (b *EndpointSpec) produces() string {
+	if b.Produces != "" {
+		return b.Produces
+	}
+	return "application/json"
+}
+
+// successStatus returns the endpoint's default success status code
+func (b *EndpointSpec) successStatus() int {
+	if b.SuccessStatus != 0 {
+		return b.SuccessStatus
+	}
+	return http.StatusOK
+}
+
 // Use adds one or more middleware functions to the endpoint
 // Middleware is applied in the order it's added (first added = outermost wrapper)
 // Example: Use(logging, auth, ratelimit) wraps as logging(auth(ratelimit(handler)))
@@ -119,30 +352,100 @@ func (b *EndpointSpec) getSpec() *EndpointSpec {
 	return b
 }
 
+// FieldSpec describes a single bound request field and its validation rules
+type FieldSpec struct {
+	Name       string       // Name of the header/route/query/form parameter or JSON body field
+	SourceType string       // "header", "route", "query", "cookie", "form", "trailer", "body"
+	Type       reflect.Type // Go type of the field
+	Validate   string       // Raw validate tag
+	Required   bool         // True if the validate tag contains "required"
+}
+
+// RequestSchema returns the bound request fields with their sources and validation rules
+// This is useful for tooling such as client-side validation generators
+func (b *EndpointSpec) RequestSchema() []FieldSpec {
+	if b.parser == nil {
+		return nil
+	}
+
+	parser := b.parser
+	fields := make([]FieldSpec, 0, len(parser.fieldParsers))
+
+	for _, fp := range parser.fieldParsers {
+		structField := parser.requestType.Field(fp.fieldIndex).Type.FieldByIndex(fp.nestedFieldIndex)
+		fields = append(fields, newFieldSpec(fp.sourceName, fp.sourceType, structField))
+	}
+
+	if parser.hasBodyField {
+		bodyType := parser.requestType.Field(parser.bodyFieldIdx).Type
+		for i := 0; i < bodyType.NumField(); i++ {
+			structField := bodyType.Field(i)
+			if !structField.IsExported() {
+				continue
+			}
+
+			name := structField.Name
+			if jsonName, _, _ := strings.Cut(structField.Tag.Get("json"), ","); jsonName != "" {
+				if jsonName == "-" {
+					continue
+				}
+				name = jsonName
+			}
+
+			fields = append(fields, newFieldSpec(name, "body", structField))
+		}
+	}
+
+	return fields
+}
+
+// newFieldSpec builds a FieldSpec from a struct field
+func newFieldSpec(name, sourceType string, structField reflect.StructField) FieldSpec {
+	validateTag := structField.Tag.Get("validate")
+	return FieldSpec{
+		Name:       name,
+		SourceType: sourceType,
+		Type:       structField.Type,
+		Validate:   validateTag,
+		Required:   strings.Contains(validateTag, "required"),
+	}
+}
+
 // fieldParser holds pre-computed parsing logic for a field
 type fieldParser struct {
 	fieldIndex       int
-	nestedFieldIndex int  // Index of the field within the nested struct
-	isNested         bool // True if this field is nested within Route/Header/Query/Form/Body
+	nestedFieldIndex []int // Index path of the field within the nested struct (longer for embedded structs)
+	isNested         bool  // True if this field is nested within Route/Header/Query/Cookie/Form/Trailer/Body
 	fieldType        reflect.Type
 	fieldKind        reflect.Kind
 
 	// Parsing configuration
-	sourceType string // "header", "route", "query", "body", "form"
+	sourceType string // "header", "route", "query", "cookie", "body", "form", "trailer"
 	sourceName string // The name of the header/route/query/form parameter
 
 	// Pre-computed setter function (avoids reflection on hot path)
 	setter      func(fieldValue reflect.Value, strValue string) error
 	isSlice     bool // True if this field is a slice (for query arrays)
-	isFileField bool // True if this field is a FileField (for file uploads)
+	isFileField bool // True if this field is a FileField or []FileField (for file uploads)
+	isCatchAll  bool // True if this field collects undeclared query parameters (query:"*")
+	isRawQuery  bool // True if this field receives the raw query string (query:"__raw__")
+
+	// Value of the default tag, bound when the parameter is absent or empty
+	defaultValue string
+	hasDefault   bool
 }
 
 // requestParser holds all pre-computed parsing logic for a request type
 type requestParser struct {
 	requestType  reflect.Type
 	fieldParsers []fieldParser
+	queryNames   map[string]bool // Declared query parameter names, excluded from catch-all maps
 	hasBodyField bool
 	bodyFieldIdx int
+	streamBody   bool // Body is an io.Reader that receives the raw stream
+	hasFormField bool
+
+	hasTrailerFields bool
 }
 
 // Responder is an interface for custom responses that need control over status codes and headers
@@ -150,6 +453,191 @@ type Responder interface {
 	WriteResponse(w http.ResponseWriter)
 }
 
+// RequestResponder is a Responder whose response depends on the request, e.g. conditional responses
+// It's written with WriteResponseFor instead of WriteResponse
+type RequestResponder interface {
+	Responder
+	WriteResponseFor(w http.ResponseWriter, r *http.Request)
+}
+
+// TextContentType is the content type of plain text responses
+const TextContentType = "text/plain; charset=utf-8"
+
+// textResponse is a Responder writing a plain text body
+type textResponse struct {
+	status int
+	text   string
+}
+
+// WriteResponse implements Responder
+func (t textResponse) WriteResponse(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", TextContentType)
+	w.WriteHeader(t.status)
+	io.WriteString(w, t.text)
+}
+
+// TextResponse returns a Responder that writes text as text/plain with the given status
+func TextResponse(status int, text string) Responder {
+	return textResponse{status: status, text: text}
+}
+
+// ChunkedResponse is a Responder that streams lines to the client as they're produced,
+// flushing after each one. The response completes when Stream returns
+// Behind middleware.Timeout, which buffers responses, the lines arrive together at the end
+// Example:
+//
+//	return framework.ChunkedResponse{Stream: func(write func(string) error) error {
+//		for i := range steps {
+//			if err := write(fmt.Sprintf("step %d done", i)); err != nil {
+//				return err
+//			}
+//		}
+//		return nil
+//	}}, nil
+type ChunkedResponse struct {
+	// ContentType defaults to text/plain; charset=utf-8
+	ContentType string
+	// Stream produces the response; write sends one line and fails once the client is gone
+	// The status is already sent when Stream runs, so a returned error just ends the response
+	Stream func(write func(line string) error) error
+}
+
+// WriteResponse implements Responder
+func (c ChunkedResponse) WriteResponse(w http.ResponseWriter) {
+	contentType := c.ContentType
+	if contentType == "" {
+		contentType = TextContentType
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	controller := http.NewResponseController(w)
+	controller.Flush()
+
+	_ = c.Stream(func(line string) error {
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n"
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+		// Writers that can't flush (e.g. behind middleware.Timeout) deliver the lines at the end
+		if err := controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		return nil
+	})
+}
+
+// BinaryContentType is the default content type of file downloads
+const BinaryContentType = "application/octet-stream"
+
+// FileResponse is a Responder that sends a file download
+// Return it from a handler and the OpenAPI response is documented as binary:
+//
+//	return framework.FileResponse{Filename: "report.pdf", ContentType: "application/pdf", Content: file}, nil
+type FileResponse struct {
+	// ContentType defaults to application/octet-stream
+	ContentType string
+	// Filename, if set, is sent in a Content-Disposition attachment header
+	Filename string
+	// Content is copied to the client and closed afterwards if it's an io.Closer
+	Content io.Reader
+}
+
+// WriteResponse implements Responder
+func (file FileResponse) WriteResponse(w http.ResponseWriter) {
+	if closer, ok := file.Content.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	contentType := file.ContentType
+	if contentType == "" {
+		contentType = BinaryContentType
+	}
+	w.Header().Set("Content-Type", contentType)
+	if file.Filename != "" {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Filename}))
+	}
+	w.WriteHeader(http.StatusOK)
+
+	if file.Content != nil {
+		io.Copy(w, file.Content)
+	}
+}
+
+// LastModifiedResponse is a RequestResponder that sends Body as JSON with a Last-Modified
+// header, or 304 Not Modified when the request's If-Modified-Since isn't older than ModTime
+// Body gets the response envelope and indentation like any other JSON response
+// Example: return framework.LastModifiedResponse[Article]{ModTime: article.UpdatedAt, Body: article}, nil
+type LastModifiedResponse[T any] struct {
+	ModTime time.Time
+	Body    T
+}
+
+// WriteResponse implements Responder, always sending the body
+func (resp LastModifiedResponse[T]) WriteResponse(w http.ResponseWriter) {
+	resp.WriteResponseFor(w, nil)
+}
+
+// WriteResponseFor implements RequestResponder
+func (resp LastModifiedResponse[T]) WriteResponseFor(w http.ResponseWriter, r *http.Request) {
+	resp.writeResponseWith(w, r, func(w io.Writer, body any) error {
+		return json.NewEncoder(w).Encode(body)
+	})
+}
+
+// writeResponseWith implements bodyEncodingResponder
+func (resp LastModifiedResponse[T]) writeResponseWith(w http.ResponseWriter, r *http.Request, encode func(io.Writer, any) error) {
+	if !resp.ModTime.IsZero() {
+		w.Header().Set("Last-Modified", resp.ModTime.UTC().Format(http.TimeFormat))
+	}
+	if r != nil && notModifiedSince(r, resp.ModTime) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	encode(w, resp.Body)
+}
+
+// bodyEncodingResponder is a RequestResponder whose JSON body is encoded by the framework,
+// so it gets the response envelope and indentation like plain responses
+type bodyEncodingResponder interface {
+	writeResponseWith(w http.ResponseWriter, r *http.Request, encode func(io.Writer, any) error)
+}
+
+// ResponseBodyType returns the type of Body, so the OpenAPI spec documents the body rather than the wrapper
+func (resp LastModifiedResponse[T]) ResponseBodyType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+// notModifiedSince reports whether r's If-Modified-Since allows a 304 for a resource last modified at modTime
+// Like http.ServeContent, it only applies to GET and HEAD requests without If-None-Match
+func notModifiedSince(r *http.Request, modTime time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if modTime.IsZero() || r.Header.Get("If-None-Match") != "" {
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	// Last-Modified has second precision
+	return !modTime.Truncate(time.Second).After(since)
+}
+
+// StatusCoder is implemented by responses (and errors) that choose their own HTTP status code
+// Example: func (CreateUserResponse) StatusCode() int { return http.StatusCreated }
+type StatusCoder interface {
+	StatusCode() int
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error   string            `json:"error"`
@@ -159,7 +647,7 @@ type ErrorResponse struct {
 // ValidationError represents a validation error for a specific field
 type ValidationError struct {
 	Field      string   `json:"field"`
-	SourceType string   `json:"source_type,omitempty"` // "header", "query", "route", "body"
+	SourceType string   `json:"source_type,omitempty"` // "header", "query", "route", "cookie", "body", "trailer"
 	Errors     []string `json:"errors"`
 }
 
@@ -169,6 +657,20 @@ type ValidationErrorResponse struct {
 	Fields []ValidationError `json:"fields,omitempty"`
 }
 
+// ProblemContentType is the media type of RFC 7807 problem details responses
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails represents an RFC 7807 problem details error response
+// It is used instead of ErrorResponse when problem details mode is enabled
+type ProblemDetails struct {
+	Type    string            `json:"type"`
+	Title   string            `json:"title"`
+	Status  int               `json:"status"`
+	Detail  string            `json:"detail,omitempty"`
+	Details map[string]string `json:"details,omitempty"`
+	Fields  []ValidationError `json:"fields,omitempty"`
+}
+
 // validationErrorWrapper wraps validation errors to implement the error interface
 type validationErrorWrapper struct {
 	validationErrors []ValidationError
@@ -182,13 +684,332 @@ func (v *validationErrorWrapper) ValidationErrors() []ValidationError {
 	return v.validationErrors
 }
 
+// Validator validates parsed requests
+// Validate receives the parsed request struct by value. Errors implementing
+// interface{ ValidationErrors() []ValidationError } are reported field by field like
+// the built-in validator's; any other error is reported as a 400 with its message
+type Validator interface {
+	Validate(req any) error
+}
+
+// playgroundValidator is the default Validator, driven by go-playground validate tags
+type playgroundValidator struct {
+	validate *validator.Validate
+}
+
+// Validate validates req against its validate tags
+func (v playgroundValidator) Validate(req any) error {
+	return v.validate.Struct(req)
+}
+
+// ValidateExcept validates req against its validate tags, skipping the named fields
+func (v playgroundValidator) ValidateExcept(req any, fields ...string) error {
+	return v.validate.StructExcept(req, fields...)
+}
+
+// PartialValidator is a Validator that can skip top-level fields of the request
+// Request types with both Body and Form bind only one of them per request, and the
+// unbound one is skipped so its required fields don't fail. Engines that don't
+// implement it validate the whole request
+type PartialValidator interface {
+	Validator
+	ValidateExcept(req any, fields ...string) error
+}
+
 // New creates a new Framework instance
 func New() *Framework {
+	return NewWithMux(http.NewServeMux())
+}
+
+// NewWithMux creates a new framework instance that registers its routes on mux
+// Patterns are passed as "METHOD /path/{param}". Route parameters are read with
+// r.PathValue, so the mux must populate them (e.g. via r.SetPathValue)
+func NewWithMux(mux Mux) *Framework {
 	return &Framework{
-		mux:       http.NewServeMux(),
-		validator: validator.New(),
+		mux:       mux,
+		validator: playgroundValidator{validate: validator.New()},
 		endpoints: make([]*EndpointSpec, 0),
+
+		validationStatus: http.StatusBadRequest,
+		routeMethods:     make(map[string][]string),
+		optionsHandlers:  make(map[string]http.Handler),
+		optionsFallbacks: make(map[string]http.Handler),
+	}
+}
+
+// SetMaxMultipartFiles limits the number of file parts accepted in a multipart request
+// Requests carrying more files than allowed are rejected with 400 Bad Request
+// A value of 0 (the default) means no limit
+func (f *Framework) SetMaxMultipartFiles(n int) {
+	f.maxMultipartFiles = n
+}
+
+// DefaultMaxMultipartMemory is the number of bytes of a multipart body kept in memory by default
+const DefaultMaxMultipartMemory = 32 << 20
+
+// SetMaxMultipartMemory sets how many bytes of a multipart body are kept in memory
+// File parts beyond the limit are stored in temporary files, so larger uploads still succeed.
+// It doesn't bound the upload size; use SetMaxRequestSize for a hard limit
+// A value of 0 restores DefaultMaxMultipartMemory
+func (f *Framework) SetMaxMultipartMemory(n int64) {
+	f.maxMultipartMemory = n
+}
+
+// multipartMemory returns the configured multipart memory limit
+func (f *Framework) multipartMemory() int64 {
+	if f.maxMultipartMemory > 0 {
+		return f.maxMultipartMemory
+	}
+	return DefaultMaxMultipartMemory
+}
+
+// SetMaxRequestSize limits request bodies to n bytes, responding with 413 Payload Too Large
+// Requests declaring a larger Content-Length are rejected before any of the body is read;
+// bodies without a declared length are cut off once they exceed n
+// A value of 0 (the default) means no limit
+func (f *Framework) SetMaxRequestSize(n int64) {
+	f.maxRequestSize = n
+}
+
+// SetResponseEnvelope wraps every successful JSON response body using fn
+// Example: SetResponseEnvelope(func(data any) any { return map[string]any{"data": data} })
+// Endpoints can opt out with SetDisableEnvelope(true)
+func (f *Framework) SetResponseEnvelope(fn func(data any) any) {
+	f.envelope = fn
+}
+
+// SetValidationStatus sets the status code used for validation failures
+// Defaults to 400 Bad Request; use 422 Unprocessable Entity to distinguish semantically
+// invalid requests from malformed ones (which always return 400)
+func (f *Framework) SetValidationStatus(code int) {
+	f.validationStatus = code
+}
+
+// SetValidatorEngine replaces the go-playground validator used to validate requests,
+// e.g. with an adapter for ozzo-validation. A nil v restores the default
+func (f *Framework) SetValidatorEngine(v Validator) {
+	if v == nil {
+		v = playgroundValidator{validate: validator.New()}
 	}
+	f.validator = v
+}
+
+// ValidationStatus returns the status code used for validation failures
+func (f *Framework) ValidationStatus() int {
+	return f.validationStatus
+}
+
+// SetProblemDetails switches error responses to RFC 7807 problem details
+// (application/problem+json) instead of the default ErrorResponse shape
+func (f *Framework) SetProblemDetails(enabled bool) {
+	f.problemDetails = enabled
+}
+
+// ProblemDetails reports whether error responses use RFC 7807 problem details
+func (f *Framework) ProblemDetails() bool {
+	return f.problemDetails
+}
+
+// SetPreParseHook registers a function that runs before the request is parsed
+// It receives the raw request, which makes it the place to verify signatures before the body is consumed
+// A non-nil error rejects the request with 400, or with the error's status if it implements StatusCoder
+func (f *Framework) SetPreParseHook(fn func(r *http.Request) error) {
+	f.preParseHook = fn
+}
+
+// SetPostParseHook registers a hook that runs after the request is parsed and before it's validated
+// The hook receives a pointer to the parsed request struct, so it can normalize fields in place
+// (e.g. trim whitespace). Returning an error rejects the request with 400 Bad Request, or with
+// the error's status code if it implements StatusCoder
+func (f *Framework) SetPostParseHook(fn func(ctx context.Context, req any) error) {
+	f.postParseHook = fn
+}
+
+// Plugin hooks into the phases of request handling by implementing any of
+// ParsePlugin, ValidatePlugin and RespondPlugin
+type Plugin any
+
+// ParsePlugin runs after the request is parsed, before validation
+// req is a pointer to the request struct; returning an error rejects the request with 400
+// (or the error's StatusCode)
+type ParsePlugin interface {
+	OnParse(ctx context.Context, req any) error
+}
+
+// ValidatePlugin runs after the request passes validation (or skips it), before the handler
+// Returning an error rejects the request with the validation status (or the error's StatusCode)
+type ValidatePlugin interface {
+	OnValidate(ctx context.Context, req any) error
+}
+
+// RespondPlugin observes the handler's response and error before the response is written
+type RespondPlugin interface {
+	OnRespond(ctx context.Context, response any, err error)
+}
+
+// UsePlugin registers a plugin for every endpoint, in addition to the hooks set with
+// SetPostParseHook. Plugins run in the order they're registered
+// Example: app.UsePlugin(metricsPlugin)
+func (f *Framework) UsePlugin(plugin Plugin) *Framework {
+	f.plugins = append(f.plugins, plugin)
+	return f
+}
+
+// runParsePlugins runs the OnParse hooks, stopping at the first error
+func (f *Framework) runParsePlugins(ctx context.Context, req any) error {
+	for _, plugin := range f.plugins {
+		if parsePlugin, ok := plugin.(ParsePlugin); ok {
+			if err := parsePlugin.OnParse(ctx, req); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// runValidatePlugins runs the OnValidate hooks, stopping at the first error
+func (f *Framework) runValidatePlugins(ctx context.Context, req any) error {
+	for _, plugin := range f.plugins {
+		if validatePlugin, ok := plugin.(ValidatePlugin); ok {
+			if err := validatePlugin.OnValidate(ctx, req); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// runRespondPlugins runs the OnRespond hooks
+func (f *Framework) runRespondPlugins(ctx context.Context, response any, err error) {
+	for _, plugin := range f.plugins {
+		if respondPlugin, ok := plugin.(RespondPlugin); ok {
+			respondPlugin.OnRespond(ctx, response, err)
+		}
+	}
+}
+
+// BodyDecoder decodes a request body into v, a pointer to the Body type
+type BodyDecoder func(body io.Reader, v any) error
+
+// RegisterBodyDecoder decodes Body fields of requests with the given media type using
+// decode instead of JSON, e.g. RegisterBodyDecoder("application/xml", xmlDecode)
+// Decoded bodies get the same defaults and validation as JSON ones
+func (f *Framework) RegisterBodyDecoder(mediaType string, decode BodyDecoder) {
+	if f.bodyDecoders == nil {
+		f.bodyDecoders = make(map[string]BodyDecoder)
+	}
+	f.bodyDecoders[strings.ToLower(mediaType)] = decode
+}
+
+// SetClientTimeout lets clients bound the handler's context with a duration sent in the
+// given header, e.g. SetClientTimeout("X-Request-Timeout", 30*time.Second) and
+// "X-Request-Timeout: 5s". Requested timeouts are clamped to max (0 means no bound), and
+// the endpoint's own timeout still applies when it's shorter. Handlers still running at
+// the deadline get 503 Service Unavailable; unparsable values get 400
+// An empty header disables client timeouts (the default)
+func (f *Framework) SetClientTimeout(header string, max time.Duration) {
+	f.clientTimeoutHeader = header
+	f.maxClientTimeout = max
+}
+
+// requestTimeout returns the timeout for r: the shorter of the endpoint's timeout and the
+// client-requested one, if enabled and sent
+func (f *Framework) requestTimeout(r *http.Request, endpointTimeout time.Duration) (time.Duration, error) {
+	if f.clientTimeoutHeader == "" {
+		return endpointTimeout, nil
+	}
+	value := r.Header.Get(f.clientTimeoutHeader)
+	if value == "" {
+		return endpointTimeout, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid %s header: expected a positive duration such as 5s", f.clientTimeoutHeader)
+	}
+	if f.maxClientTimeout > 0 {
+		timeout = min(timeout, f.maxClientTimeout)
+	}
+	if endpointTimeout > 0 {
+		timeout = min(timeout, endpointTimeout)
+	}
+	return timeout, nil
+}
+
+// SetLenientBody enables lenient JSON body decoding
+// In lenient mode string-encoded numbers and booleans (e.g. "age": "30") are coerced
+// to the field's type. The default is strict decoding
+func (f *Framework) SetLenientBody(enabled bool) {
+	f.lenientBody = enabled
+}
+
+// SetIndent pretty-prints JSON responses using the given prefix and indent
+// The default is compact output
+// Example: app.SetIndent("", "  ")
+func (f *Framework) SetIndent(prefix, indent string) {
+	f.indentPrefix = prefix
+	f.indent = indent
+}
+
+// newEncoder returns a JSON encoder for w using the configured indentation
+func (f *Framework) newEncoder(w io.Writer) *json.Encoder {
+	encoder := json.NewEncoder(w)
+	if f.indentPrefix != "" || f.indent != "" {
+		encoder.SetIndent(f.indentPrefix, f.indent)
+	}
+	return encoder
+}
+
+// SetStrictRegistration makes endpoint registration panic on request types that can't bind
+// as intended: a required Route field without a matching {param} in the path, or a
+// Route, Query or Header field without a json tag naming its parameter
+// Enable it in development and tests to catch mistakes at startup instead of on every request
+func (f *Framework) SetStrictRegistration(enabled bool) {
+	f.strictRegistration = enabled
+}
+
+// SetStrictAccept rejects requests whose Accept header doesn't allow the endpoint's
+// response content type with 406 Not Acceptable. Requests without an Accept header are allowed
+func (f *Framework) SetStrictAccept(enabled bool) {
+	f.strictAccept = enabled
+}
+
+// errorMapping maps handler errors matching target to a status code and message
+type errorMapping struct {
+	target  error
+	code    int
+	message string
+}
+
+// MapError responds with code when a handler returns an error matching target (via errors.Is)
+// An empty message uses the error's own text. Mappings are checked in registration order
+// Example: app.MapError(ErrQuotaExceeded, http.StatusTooManyRequests, "quota exceeded")
+func (f *Framework) MapError(target error, code int, message string) {
+	f.errorMappings = append(f.errorMappings, errorMapping{target: target, code: code, message: message})
+}
+
+// mapError returns the status code and message for a handler error
+// Unmapped errors are internal server errors
+func (f *Framework) mapError(err error) (int, string) {
+	for _, mapping := range f.errorMappings {
+		if errors.Is(err, mapping.target) {
+			if mapping.message == "" {
+				return mapping.code, err.Error()
+			}
+			return mapping.code, mapping.message
+		}
+	}
+	return http.StatusInternalServerError, err.Error()
+}
+
+// SetAutoOptions answers OPTIONS requests for every registered path with 204 No Content
+// and an Allow header listing the path's methods
+// The response passes through the framework middlewares and those of the group that first
+// registered the path, so CORS middleware can answer preflight requests for routes without
+// an OPTIONS handler
+// Explicit OPTIONS endpoints still take precedence. Enable it before registering routes
+func (f *Framework) SetAutoOptions(enabled bool) {
+	f.autoOptions = enabled
 }
 
 // getFramework implements Router interface for Framework
@@ -202,17 +1023,25 @@ func (f *Framework) getPrefix() string {
 }
 
 // getMiddlewares implements Router interface for Framework
-func (f *Framework) getMiddlewares() []Middleware {
+func (f *Framework) getMiddlewares(method string) []Middleware {
 	return nil
 }
 
+// Use adds middleware applied to every endpoint, whether registered on the framework or a group
+// Framework middleware runs outermost, before group and endpoint middleware
+// It applies to endpoints registered after the call
+func (f *Framework) Use(middleware ...Middleware) *Framework {
+	f.middlewares = append(f.middlewares, middleware...)
+	return f
+}
+
 // Group creates a new route group with the given path prefix
 // Example: api := app.Group("/api/v1")
 func (f *Framework) Group(prefix string) *Group {
 	return &Group{
 		framework:   f,
 		prefix:      prefix,
-		middlewares: make([]Middleware, 0),
+		middlewares: make([]groupMiddleware, 0),
 	}
 }
 
@@ -227,14 +1056,32 @@ func (g *Group) getPrefix() string {
 }
 
 // getMiddlewares implements Router interface for Group
-func (g *Group) getMiddlewares() []Middleware {
-	return g.middlewares
+// It returns the group middleware that applies to endpoints with the given method
+func (g *Group) getMiddlewares(method string) []Middleware {
+	middlewares := make([]Middleware, 0, len(g.middlewares))
+	for _, mw := range g.middlewares {
+		if mw.methods == nil || slices.Contains(mw.methods, method) {
+			middlewares = append(middlewares, mw.middleware)
+		}
+	}
+	return middlewares
 }
 
 // Use adds middleware to the group
 // All routes registered on this group will have this middleware applied
 func (g *Group) Use(middleware ...Middleware) *Group {
-	g.middlewares = append(g.middlewares, middleware...)
+	for _, mw := range middleware {
+		g.middlewares = append(g.middlewares, groupMiddleware{middleware: mw})
+	}
+	return g
+}
+
+// UseForMethods adds middleware applied only to the group's routes with one of the given methods
+// Example: api.UseForMethods([]string{http.MethodPost, http.MethodPut}, RateLimit)
+func (g *Group) UseForMethods(methods []string, middleware ...Middleware) *Group {
+	for _, mw := range middleware {
+		g.middlewares = append(g.middlewares, groupMiddleware{middleware: mw, methods: slices.Clone(methods)})
+	}
 	return g
 }
 
@@ -245,7 +1092,18 @@ func (g *Group) Group(prefix string) *Group {
 	return &Group{
 		framework:   g.framework,
 		prefix:      g.prefix + prefix,
-		middlewares: append([]Middleware{}, g.middlewares...), // Copy parent middlewares
+		middlewares: append([]groupMiddleware{}, g.middlewares...), // Copy parent middlewares
+	}
+}
+
+// GroupClean creates a sub-group with an additional path prefix but without the
+// parent group's middleware, e.g. for public routes under an authenticated group
+// Framework-level middleware registered with Framework.Use still applies
+// Example: public := api.GroupClean("/public")
+func (g *Group) GroupClean(prefix string) *Group {
+	return &Group{
+		framework: g.framework,
+		prefix:    g.prefix + prefix,
 	}
 }
 
@@ -260,14 +1118,15 @@ func CreateEndpoint[TReq any, TResp any](method, path string, handler func(ctx c
 	var reqExample TReq
 	route.RequestType = reflect.TypeOf(reqExample)
 
-	var respExample TResp
-	route.ResponseType = reflect.TypeOf(respExample)
+	// TypeFor keeps interface response types (like Responder) instead of returning nil
+	route.ResponseType = reflect.TypeFor[TResp]()
 
 	// Build request parser plan at registration time (expensive reflection here)
 	parser := buildRequestParser(route.RequestType)
+	route.parser = parser
 
 	// Create HTTP handler function with pre-computed parser
-	route.handlerPrepFn = func(f *Framework) http.HandlerFunc { return createTypeSafeHandler(f, handler, parser) }
+	route.handlerPrepFn = func(f *Framework) http.HandlerFunc { return createTypeSafeHandler(f, route, handler, parser) }
 	return route
 }
 
@@ -276,26 +1135,173 @@ func RegisterEndpoint(router Router, e Endpoint) {
 
 	f := router.getFramework()
 
-	// Combine group middlewares with endpoint-specific middlewares
-	// Group middlewares should be applied first (outermost)
-	route.AllMiddlewares = append(router.getMiddlewares(), route.Middlewares...)
+	// Combine framework, group and endpoint-specific middlewares
+	// Framework middlewares are outermost, then group middlewares
+	routerMiddlewares := router.getMiddlewares(route.Method)
+	route.AllMiddlewares = make([]Middleware, 0, len(f.middlewares)+len(routerMiddlewares)+len(route.Middlewares))
+	route.AllMiddlewares = append(route.AllMiddlewares, f.middlewares...)
+	route.AllMiddlewares = append(route.AllMiddlewares, routerMiddlewares...)
+	route.AllMiddlewares = append(route.AllMiddlewares, route.Middlewares...)
 
 	// Combine group prefix with endpoint path
 	route.FullPath = router.getPrefix() + route.RelativePath
 
+	// A trailing optional segment registers the endpoint both with and without it
+	routes := []*EndpointSpec{route}
+	if withoutSegment, withSegment, ok := splitOptionalSegment(route.FullPath); ok {
+		route.FullPath = withSegment
+		short := *route
+		short.FullPath = withoutSegment
+		routes = []*EndpointSpec{&short, route}
+	}
+
 	// Apply middleware in reverse order (so first middleware added is outermost)
 	var finalHandler http.Handler = route.handlerPrepFn(f)
 	for i := len(route.AllMiddlewares) - 1; i >= 0; i-- {
 		finalHandler = route.AllMiddlewares[i](finalHandler)
 	}
+	finalHandler = trackResponse(finalHandler)
 	// route.handlerFunc = finalHandler.ServeHTTP
 
-	f.endpoints = append(f.endpoints, route)
+	for _, route := range routes {
+		if f.strictRegistration {
+			if err := checkRegistration(route); err != nil {
+				panic(fmt.Sprintf("framework: invalid endpoint %s %s: %v", route.Method, route.FullPath, err))
+			}
+		}
+
+		f.endpoints = append(f.endpoints, route)
+
+		// With auto-OPTIONS, OPTIONS endpoints are dispatched by the path's OPTIONS handler
+		if f.autoOptions {
+			f.registerAutoOptions(router, route, finalHandler)
+			if route.Method == http.MethodOptions {
+				continue
+			}
+		} else {
+			f.registerOptionsFallback(router, route.FullPath)
+		}
+
+		// Register with ServeMux using method and path pattern
+		// Go 1.22+ supports patterns like "GET /users/{id}"
+		pattern := route.Method + " " + route.FullPath
+		f.mux.Handle(pattern, finalHandler)
+	}
+}
+
+// checkRegistration reports request type mistakes that make requests to route bind incorrectly
+func checkRegistration(route *EndpointSpec) error {
+	if route.RequestType == nil || route.RequestType.Kind() != reflect.Struct {
+		return nil
+	}
+
+	// Untagged parameters silently bind by Go field name (IncludeDetails, not include_details)
+	for _, source := range []string{"Route", "Query", "Header", "Cookie"} {
+		sourceField, ok := route.RequestType.FieldByName(source)
+		if !ok || sourceField.Type.Kind() != reflect.Struct {
+			continue
+		}
+		for _, field := range NestedFields(sourceField.Type) {
+			if field.Tag.Get("json") == "" && field.Tag.Get("query") == "" {
+				return fmt.Errorf("field %s.%s has no json tag naming the parameter", source, field.Name)
+			}
+		}
+	}
+
+	routeField, ok := route.RequestType.FieldByName("Route")
+	if !ok || routeField.Type.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for _, field := range NestedFields(routeField.Type) {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		required := slices.Contains(strings.Split(field.Tag.Get("validate"), ","), "required")
+		inPath := strings.Contains(route.FullPath, "{"+name+"}") || strings.Contains(route.FullPath, "{"+name+"...}")
+		if required && !inPath {
+			return fmt.Errorf("required route parameter %q (field Route.%s) has no {%s} in the path", name, field.Name, name)
+		}
+	}
+	return nil
+}
+
+// splitOptionalSegment splits a path ending in an optional wildcard segment such as
+// "/users/{id}/posts/{postId?}" into the path without the segment ("/users/{id}/posts")
+// and the path with it as a regular wildcard ("/users/{id}/posts/{postId}")
+func splitOptionalSegment(path string) (withoutSegment, withSegment string, ok bool) {
+	slash := strings.LastIndex(path, "/")
+	segment := path[slash+1:]
+	if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "?}") {
+		return "", "", false
+	}
+
+	withoutSegment = path[:slash]
+	if withoutSegment == "" {
+		// Match only the root, not every path
+		withoutSegment = "/{$}"
+	}
+	return withoutSegment, path[:len(path)-2] + "}", true
+}
+
+// registerAutoOptions records the route's method for its path and registers one OPTIONS
+// handler per path, which runs an explicit OPTIONS endpoint if there is one and otherwise
+// responds with the path's Allow header
+func (f *Framework) registerAutoOptions(router Router, route *EndpointSpec, handler http.Handler) {
+	path := route.FullPath
+	_, registered := f.routeMethods[path]
+
+	f.routeMethods[path] = append(f.routeMethods[path], route.Method)
+	if route.Method == http.MethodOptions {
+		f.optionsHandlers[path] = handler
+	}
+	if registered {
+		return
+	}
+
+	var autoHandler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", f.allowedMethods(path))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	middlewares := append(slices.Clone(f.middlewares), router.getMiddlewares(http.MethodOptions)...)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		autoHandler = middlewares[i](autoHandler)
+	}
+
+	f.mux.Handle(http.MethodOptions+" "+path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if explicit, ok := f.optionsHandlers[path]; ok {
+			explicit.ServeHTTP(w, r)
+			return
+		}
+		autoHandler.ServeHTTP(w, r)
+	}))
+}
+
+// registerOptionsFallback records the 405 response to OPTIONS requests for path, wrapped
+// in the framework middlewares and those of the group that first registered the path
+// Without auto-OPTIONS this still lets CORS middleware answer preflight requests
+func (f *Framework) registerOptionsFallback(router Router, path string) {
+	if _, registered := f.optionsFallbacks[path]; registered {
+		return
+	}
+
+	var fallback http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.writeError(w, http.StatusMethodNotAllowed, "method not allowed", nil)
+	})
+	middlewares := append(slices.Clone(f.middlewares), router.getMiddlewares(http.MethodOptions)...)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		fallback = middlewares[i](fallback)
+	}
+	f.optionsFallbacks[path] = fallback
+}
 
-	// Register with ServeMux using method and path pattern
-	// Go 1.22+ supports patterns like "GET /users/{id}"
-	pattern := route.Method + " " + route.FullPath
-	f.mux.Handle(pattern, finalHandler)
+// allowedMethods returns the Allow header value for path
+func (f *Framework) allowedMethods(path string) string {
+	methods := append([]string{http.MethodOptions}, f.routeMethods[path]...)
+	// The mux serves HEAD requests with GET handlers
+	if slices.Contains(methods, http.MethodGet) {
+		methods = append(methods, http.MethodHead)
+	}
+	slices.Sort(methods)
+	return strings.Join(slices.Compact(methods), ", ")
 }
 
 // RegisterHandlerRoute registers a new endpoint with type-safe handler and middleware
@@ -305,6 +1311,39 @@ func RegisterHandlerRoute[TReq any, TResp any](router Router, method, path strin
 	RegisterEndpoint(router, ep)
 }
 
+// parseRequestFramework is the default-configured framework used by ParseRequest and DecodeQuery
+var parseRequestFramework = sync.OnceValue(New)
+
+// parseRequestParsers caches request parsers by request type for ParseRequest
+var parseRequestParsers sync.Map
+
+// ParseRequest parses and validates r into a Req using the default framework configuration
+// It runs the same binding logic as registered endpoints without needing a server,
+// which makes it usable from tests and fuzz targets. Validation failures return an
+// error implementing interface{ ValidationErrors() []ValidationError }
+// Route values are read with r.PathValue, so set them with r.SetPathValue when the
+// request doesn't come through the mux
+func ParseRequest[Req any](r *http.Request) (Req, error) {
+	var req Req
+	reqValue := reflect.ValueOf(&req).Elem()
+
+	cached, ok := parseRequestParsers.Load(reqValue.Type())
+	if !ok {
+		cached, _ = parseRequestParsers.LoadOrStore(reqValue.Type(), buildRequestParser(reqValue.Type()))
+	}
+	parser := cached.(*requestParser)
+
+	f := parseRequestFramework()
+	if err := f.parseWithPlan(r, reqValue, parser); err != nil {
+		return req, err
+	}
+	if err := f.validateRequest(r, reqValue, parser); err != nil {
+		return req, err
+	}
+
+	return req, nil
+}
+
 // buildRequestParser builds a pre-computed parser plan for a request type
 // This function does all the expensive reflection work at registration time
 func buildRequestParser(reqType reflect.Type) *requestParser {
@@ -325,7 +1364,7 @@ func buildRequestParser(reqType reflect.Type) *requestParser {
 		fieldName := field.Name
 		fieldKind := field.Type.Kind()
 
-		// Check if this is a nested struct for Route, Header, Query, Form, or Body
+		// Check if this is a nested struct for Route, Header, Query, Cookie, Form, Trailer, or Body
 		if fieldKind == reflect.Struct {
 			switch fieldName {
 			case "Route":
@@ -334,30 +1373,59 @@ func buildRequestParser(reqType reflect.Type) *requestParser {
 				parseNestedStruct(parser, field.Type, i, "header")
 			case "Query":
 				parseNestedStruct(parser, field.Type, i, "query")
+			case "Cookie":
+				parseNestedStruct(parser, field.Type, i, "cookie")
 			case "Form":
+				parser.hasFormField = true
 				parseNestedStructForForm(parser, field.Type, i)
+			case "Trailer":
+				parser.hasTrailerFields = true
+				parseNestedStruct(parser, field.Type, i, "trailer")
 			case "Body":
 				parser.hasBodyField = true
 				parser.bodyFieldIdx = i
+				if err := checkDefaults(field.Type); err != nil {
+					panic(fmt.Sprintf("framework: body: %v", err))
+				}
 			}
 		}
+
+		// A pointer Body stays nil when the request has no body
+		if fieldName == "Body" && fieldKind == reflect.Pointer && field.Type.Elem().Kind() == reflect.Struct {
+			parser.hasBodyField = true
+			parser.bodyFieldIdx = i
+			if err := checkDefaults(field.Type); err != nil {
+				panic(fmt.Sprintf("framework: body: %v", err))
+			}
+		}
+
+		// A Body declared as io.Reader (or io.ReadCloser) receives the raw body stream
+		if fieldName == "Body" && isStreamBodyType(field.Type) {
+			parser.hasBodyField = true
+			parser.bodyFieldIdx = i
+			parser.streamBody = true
+		}
+	}
+
+	// Trailers arrive after the body, so binding them would drain the stream before the
+	// handler could read it
+	if parser.streamBody && parser.hasTrailerFields {
+		panic("framework: Trailer fields can't be combined with a streamed io.Reader Body")
 	}
 
 	return parser
 }
 
+// isStreamBodyType reports whether t is a reader interface that the raw request body satisfies
+func isStreamBodyType(t reflect.Type) bool {
+	return t.Kind() == reflect.Interface && t.NumMethod() > 0 && reflect.TypeFor[io.ReadCloser]().Implements(t)
+}
+
 // parseNestedStruct parses a nested struct (Route, Header, Query) and extracts fields using json tags
 func parseNestedStruct(parser *requestParser, structType reflect.Type, parentIndex int, sourceType string) {
-	for j := 0; j < structType.NumField(); j++ {
-		nestedField := structType.Field(j)
-
-		// Skip unexported fields
-		if !nestedField.IsExported() {
-			continue
-		}
-
-		// Get the json tag for the field name
-		jsonTag := nestedField.Tag.Get("json")
+	for _, nestedField := range NestedFields(structType) {
+		// Get the json tag for the field name, without options such as omitempty
+		jsonTag, _, _ := strings.Cut(nestedField.Tag.Get("json"), ",")
 		if jsonTag == "" {
 			// If no json tag, use the field name
 			jsonTag = nestedField.Name
@@ -375,58 +1443,186 @@ func parseNestedStruct(parser *requestParser, structType reflect.Type, parentInd
 		}
 
 		// Create pre-computed setter for this field type
-		setter := createFieldSetter(fieldKind)
+		elemType := fieldType
+		if isSlice {
+			elemType = fieldType.Elem()
+		}
+		setter := normalizedSetter(createSetter(elemType, nestedField.Tag.Get("format")), nestedField, sourceType, jsonTag)
+
+		// A map[string]string tagged query:"*" collects the query parameters not bound elsewhere
+		isCatchAll := sourceType == "query" && nestedField.Tag.Get("query") == "*" &&
+			fieldType == reflect.TypeFor[map[string]string]()
+		// A string tagged query:"__raw__" receives the query string verbatim
+		isRawQuery := sourceType == "query" && nestedField.Tag.Get("query") == "__raw__" &&
+			fieldType.Kind() == reflect.String
+		if sourceType == "query" && !isCatchAll && !isRawQuery {
+			if parser.queryNames == nil {
+				parser.queryNames = make(map[string]bool)
+			}
+			parser.queryNames[jsonTag] = true
+		}
+
+		// Check the default tag now so a bad default fails at registration, not per request
+		defaultValue, hasDefault := nestedField.Tag.Lookup("default")
+		if hasDefault {
+			for _, value := range splitDefault(defaultValue, isSlice) {
+				if err := setter(reflect.New(elemType).Elem(), value); err != nil {
+					panic(fmt.Sprintf("framework: invalid default %q for %s '%s': %v", defaultValue, sourceType, jsonTag, err))
+				}
+			}
+		}
 
 		parser.fieldParsers = append(parser.fieldParsers, fieldParser{
 			fieldIndex:       parentIndex,
-			nestedFieldIndex: j,
+			nestedFieldIndex: nestedField.Index,
 			fieldType:        nestedField.Type,
 			fieldKind:        fieldKind,
 			sourceType:       sourceType,
 			sourceName:       jsonTag,
 			setter:           setter,
 			isSlice:          isSlice,
+			isCatchAll:       isCatchAll,
+			isRawQuery:       isRawQuery,
+			defaultValue:     defaultValue,
+			hasDefault:       hasDefault,
 			isNested:         true,
 		})
 	}
 }
 
-// parseNestedStructForForm parses a nested Form struct for file uploads
-func parseNestedStructForForm(parser *requestParser, structType reflect.Type, parentIndex int) {
-	fileUploadInterface := reflect.TypeOf((*FileUpload)(nil)).Elem()
+// normalizers transform raw parameter values as named by the normalize tag
+var normalizers = map[string]func(string) string{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"trim":  strings.TrimSpace,
+}
+
+// normalizedSetter wraps setter to normalize raw values before they're parsed, as named
+// by the field's normalize tag, so e.g. oneof=asc desc accepts ?order=ASC
+// An unknown normalize value panics at registration
+func normalizedSetter(setter func(reflect.Value, string) error, field reflect.StructField, sourceType, name string) func(reflect.Value, string) error {
+	normalize, ok := field.Tag.Lookup("normalize")
+	if !ok {
+		return setter
+	}
+	fn, ok := normalizers[normalize]
+	if !ok {
+		panic(fmt.Sprintf("framework: unknown normalize %q for %s '%s' (want lower, upper or trim)", normalize, sourceType, name))
+	}
+	return func(fieldValue reflect.Value, value string) error {
+		return setter(fieldValue, fn(value))
+	}
+}
+
+// splitDefault returns the values of a default tag; slice defaults are comma-separated
+func splitDefault(defaultValue string, isSlice bool) []string {
+	if !isSlice {
+		return []string{defaultValue}
+	}
+	values := strings.Split(defaultValue, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
+
+// NestedFields returns the exported fields of a nested struct (Route, Header, Query, ...)
+// Fields of untagged embedded structs are flattened, so a shared struct such as
+// PageRequest can be embedded in Query. Each returned field's Index is its full index path
+// The OpenAPI generator uses it too, so documented parameters match the bound ones
+func NestedFields(structType reflect.Type) []reflect.StructField {
+	fields := make([]reflect.StructField, 0, structType.NumField())
 
-	for j := 0; j < structType.NumField(); j++ {
-		nestedField := structType.Field(j)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
 
 		// Skip unexported fields
-		if !nestedField.IsExported() {
+		if !field.IsExported() {
+			continue
+		}
+
+		// Flatten embedded structs without a json tag
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("json") == "" {
+			for _, embeddedField := range NestedFields(field.Type) {
+				embeddedField.Index = append([]int{i}, embeddedField.Index...)
+				fields = append(fields, embeddedField)
+			}
 			continue
 		}
 
-		// Get the json tag for the field name
-		jsonTag := nestedField.Tag.Get("json")
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// parseNestedStructForForm parses a nested Form struct for file uploads and text values
+func parseNestedStructForForm(parser *requestParser, structType reflect.Type, parentIndex int) {
+	fileFieldType := reflect.TypeFor[FileField]()
+
+	for _, nestedField := range NestedFields(structType) {
+		// Get the json tag for the field name, without options such as omitempty
+		jsonTag, _, _ := strings.Cut(nestedField.Tag.Get("json"), ",")
 		if jsonTag == "" {
 			// If no json tag, use the field name
 			jsonTag = nestedField.Name
 		}
 
-		// Check if this field implements the FileUpload interface
-		isFileField := nestedField.Type.Implements(fileUploadInterface)
+		// File uploads bind to FileField values; other FileUpload implementations
+		// (such as *FileField) can't receive them
+		isFileField := nestedField.Type == fileFieldType
+
+		fieldKind := nestedField.Type.Kind()
+		isSlice := false
+		var setter func(reflect.Value, string) error
+
+		// Slices of file fields receive every file uploaded under the name
+		if fieldKind == reflect.Slice && nestedField.Type.Elem() == fileFieldType {
+			isFileField = true
+			isSlice = true
+		}
+		if !isFileField && isOtherFileUpload(nestedField.Type) {
+			panic(fmt.Sprintf("framework: form '%s' has type %s, file uploads bind to framework.FileField or []framework.FileField", jsonTag, nestedField.Type))
+		}
+
+		// Text fields use the same setters as query parameters
+		// File fields don't use the setter
+		if !isFileField {
+			isSlice = fieldKind == reflect.Slice
+			if isSlice {
+				fieldKind = nestedField.Type.Elem().Kind()
+			}
+			elemType := nestedField.Type
+			if isSlice {
+				elemType = elemType.Elem()
+			}
+			setter = normalizedSetter(createSetter(elemType, nestedField.Tag.Get("format")), nestedField, "form", jsonTag)
+		}
 
 		parser.fieldParsers = append(parser.fieldParsers, fieldParser{
 			fieldIndex:       parentIndex,
-			nestedFieldIndex: j,
+			nestedFieldIndex: nestedField.Index,
 			fieldType:        nestedField.Type,
-			fieldKind:        nestedField.Type.Kind(),
+			fieldKind:        fieldKind,
 			sourceType:       "form",
 			sourceName:       jsonTag,
-			setter:           nil, // File fields don't use the setter
+			setter:           setter,
+			isSlice:          isSlice,
 			isFileField:      isFileField,
 			isNested:         true,
 		})
 	}
 }
 
+// isOtherFileUpload reports whether t, or the element type of a slice t, implements
+// FileUpload without being FileField
+func isOtherFileUpload(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t != reflect.TypeFor[FileField]() && t.Implements(reflect.TypeFor[FileUpload]())
+}
+
 // createFieldSetter creates a type-specific setter function at registration time
 // This avoids the switch statement in the hot path
 func createFieldSetter(kind reflect.Kind) func(reflect.Value, string) error {
@@ -438,8 +1634,8 @@ func createFieldSetter(kind reflect.Kind) func(reflect.Value, string) error {
 		}
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		return func(field reflect.Value, value string) error {
-			var intVal int64
-			if _, err := fmt.Sscanf(value, "%d", &intVal); err != nil {
+			intVal, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || field.OverflowInt(intVal) {
 				return fmt.Errorf("invalid integer value")
 			}
 			field.SetInt(intVal)
@@ -447,8 +1643,8 @@ func createFieldSetter(kind reflect.Kind) func(reflect.Value, string) error {
 		}
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		return func(field reflect.Value, value string) error {
-			var uintVal uint64
-			if _, err := fmt.Sscanf(value, "%d", &uintVal); err != nil {
+			uintVal, err := strconv.ParseUint(value, 10, 64)
+			if err != nil || field.OverflowUint(uintVal) {
 				return fmt.Errorf("invalid unsigned integer value")
 			}
 			field.SetUint(uintVal)
@@ -456,13 +1652,17 @@ func createFieldSetter(kind reflect.Kind) func(reflect.Value, string) error {
 		}
 	case reflect.Bool:
 		return func(field reflect.Value, value string) error {
-			field.SetBool(value == "true" || value == "1")
+			boolVal, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid boolean value")
+			}
+			field.SetBool(boolVal)
 			return nil
 		}
 	case reflect.Float32, reflect.Float64:
 		return func(field reflect.Value, value string) error {
-			var floatVal float64
-			if _, err := fmt.Sscanf(value, "%f", &floatVal); err != nil {
+			floatVal, err := strconv.ParseFloat(value, 64)
+			if err != nil || field.OverflowFloat(floatVal) {
 				return fmt.Errorf("invalid float value")
 			}
 			field.SetFloat(floatVal)
@@ -475,89 +1675,367 @@ func createFieldSetter(kind reflect.Kind) func(reflect.Value, string) error {
 	}
 }
 
+// createSetter creates the setter for a parameter of type t
+// time.Time values are parsed with layout, RFC 3339 when empty
+func createSetter(t reflect.Type, layout string) func(reflect.Value, string) error {
+	switch {
+	case t == reflect.TypeFor[time.Time]():
+		return createTimeSetter(layout)
+	case t.Kind() == reflect.Pointer:
+		return createPointerSetter(t.Elem(), layout)
+	default:
+		return createFieldSetter(t.Kind())
+	}
+}
+
+// createTimeSetter creates a setter parsing time.Time values with layout, RFC 3339 when empty
+// Set the layout with a format tag, e.g. `json:"from" format:"2006-01-02"`
+func createTimeSetter(layout string) func(reflect.Value, string) error {
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	return func(field reflect.Value, value string) error {
+		parsed, err := time.Parse(layout, value)
+		if err != nil {
+			return fmt.Errorf("invalid time value, expected format %s", layout)
+		}
+		field.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+}
+
+// createPointerSetter creates a setter for a pointer field that allocates the value only
+// when the parameter is present, so an absent parameter (nil) differs from an explicit zero
+// Combined with validate:"required", which checks pointers for nil, this accepts "0"
+func createPointerSetter(elemType reflect.Type, layout string) func(reflect.Value, string) error {
+	setElem := createSetter(elemType, layout)
+	return func(field reflect.Value, value string) error {
+		elem := reflect.New(elemType)
+		if err := setElem(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+}
+
 // createTypeSafeHandler creates an HTTP handler that parses and validates the request
 // This is a top-level function because Go doesn't support generic methods
 // The parser parameter contains pre-computed parsing logic, avoiding reflection on hot path
-func createTypeSafeHandler[Req any, Resp any](f *Framework, handler Handler[Req, Resp], parser *requestParser) http.HandlerFunc {
+func createTypeSafeHandler[Req any, Resp any](f *Framework, route *EndpointSpec, handler Handler[Req, Resp], parser *requestParser) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// r is a copy made by the middleware chain, so net/http's own cleanup doesn't see
+		// the parsed form; remove the temporary files of uploads spilled to disk here
+		defer func() {
+			if r.MultipartForm != nil {
+				r.MultipartForm.RemoveAll()
+			}
+		}()
+
+		// Reject declared oversized bodies before reading any bytes
+		if f.maxRequestSize > 0 {
+			if r.ContentLength > f.maxRequestSize {
+				f.writeError(w, http.StatusRequestEntityTooLarge, "request body too large", nil)
+				return
+			}
+			if r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, f.maxRequestSize)
+			}
+		}
+
+		// Enforce content negotiation before doing any work
+		if f.strictAccept && !acceptsContentType(r.Header.Get("Accept"), route.produces()) {
+			f.writeError(w, http.StatusNotAcceptable, "not acceptable: endpoint produces "+route.produces(), nil)
+			return
+		}
+		if !route.consumesRequest(r) {
+			f.writeError(w, http.StatusUnsupportedMediaType, "unsupported media type: endpoint consumes "+strings.Join(route.Consumes, ", "), nil)
+			return
+		}
+
+		// Run the pre-parse hook on the raw request
+		if f.preParseHook != nil {
+			if err := f.preParseHook(r); err != nil {
+				f.writeError(w, errorStatus(err, http.StatusBadRequest), err.Error(), nil)
+				return
+			}
+		}
+
 		// Create new instance of request struct
 		var req Req
 		reqValue := reflect.ValueOf(&req).Elem()
 
 		// Parse using pre-computed parser (fast path - minimal reflection)
-		if err := f.parseWithPlan(r, reqValue, parser); err != nil {
+		err := f.parseWithPlan(r, reqValue, parser)
+		if err == nil && f.postParseHook != nil {
+			if err := f.postParseHook(r.Context(), &req); err != nil {
+				f.writeError(w, errorStatus(err, http.StatusBadRequest), err.Error(), nil)
+				return
+			}
+		}
+		if err == nil {
+			if err := f.runParsePlugins(r.Context(), &req); err != nil {
+				f.writeError(w, errorStatus(err, http.StatusBadRequest), err.Error(), nil)
+				return
+			}
+		}
+		if err == nil && !route.SkipValidation {
+			err = f.validateRequest(r, reqValue, parser)
+		}
+		if err == nil {
+			if err := f.runValidatePlugins(r.Context(), &req); err != nil {
+				f.writeError(w, errorStatus(err, f.validationStatus), err.Error(), nil)
+				return
+			}
+		}
+		if err != nil {
+			// Check if the body exceeded an http.MaxBytesReader limit
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				f.writeError(w, http.StatusRequestEntityTooLarge, "request body too large", nil)
+				return
+			}
+
 			// Check if it's a validation error
 			if validationErr, ok := err.(*validationErrorWrapper); ok {
-				f.writeValidationError(w, http.StatusBadRequest, validationErr.ValidationErrors())
+				f.writeValidationError(w, f.validationStatus, validationErr.ValidationErrors())
 			} else {
 				f.writeError(w, http.StatusBadRequest, err.Error(), nil)
 			}
 			return
 		}
 
+		// Apply the endpoint's or the client's timeout, whichever is shorter, to the handler's context
+		timeout, err := f.requestTimeout(r, route.Timeout)
+		if err != nil {
+			f.writeError(w, http.StatusBadRequest, err.Error(), nil)
+			return
+		}
+		ctx := r.Context()
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
 		// Call the type-safe handler
-		response, err := handler(r.Context(), req)
+		response, err := handler(ctx, req)
+
+		// A middleware may have already committed the response
+		if responseCommitted(r) {
+			return
+		}
+
+		// A handler that overran its deadline timed out, whether or not it then
+		// produced a response
+		if timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			f.writeError(w, http.StatusServiceUnavailable, "request timed out", nil)
+			return
+		}
+
+		// A Warning alongside a Responder doesn't stop the Responder from being written;
+		// it's sent in a Warning header and passed to OnRespond hooks
+		var warning *Warning
+		if isResponder(response) && errors.As(err, &warning) {
+			w.Header().Add("Warning", warning.header())
+		} else {
+			warning = nil
+		}
+
+		f.runRespondPlugins(r.Context(), response, err)
+
+		// Handle errors
+		if err != nil && warning == nil {
+			code, message := f.mapError(err)
+			f.writeError(w, code, message, nil)
+			return
+		}
+
+		// Write response
+		writeResponse(f, route, w, r, response)
+	}
+}
+
+// writeResponse writes the response to the HTTP response writer
+func writeResponse[Resp any](f *Framework, route *EndpointSpec, w http.ResponseWriter, r *http.Request, response Resp) {
+	// A nil pointer Responder can't write itself, so it's encoded as JSON null
+	if isResponder(response) {
+		// Responders with JSON bodies are encoded like plain responses
+		if responder, ok := any(response).(bodyEncodingResponder); ok {
+			responder.writeResponseWith(w, r, func(w io.Writer, body any) error {
+				return f.newEncoder(w).Encode(f.wrapEnvelope(route, body))
+			})
+			return
+		}
+
+		// Responders that depend on the request get it
+		if responder, ok := any(response).(RequestResponder); ok {
+			responder.WriteResponseFor(w, r)
+			return
+		}
+
+		any(response).(Responder).WriteResponse(w)
+		return
+	}
+
+	writeJSONResponse(f, route, w, response)
+}
+
+// writeJSONResponse writes a response that isn't a Responder
+func writeJSONResponse[Resp any](f *Framework, route *EndpointSpec, w http.ResponseWriter, response Resp) {
+	// Bare strings are written as-is for text/plain endpoints instead of JSON-quoted
+	if text, ok := any(response).(string); ok && strings.HasPrefix(route.Produces, "text/plain") {
+		TextResponse(route.successStatus(), text).WriteResponse(w)
+		return
+	}
+
+	if statusResponder, ok := any(response).(StatusResponse[Resp]); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusResponder.Code)
+		f.newEncoder(w).Encode(f.wrapEnvelope(route, statusResponder.Body))
+		return
+	}
+
+	// Check if the response declares its own status code
+	if statusCoder, ok := any(response).(StatusCoder); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCoder.StatusCode())
+		f.newEncoder(w).Encode(f.wrapEnvelope(route, response))
+		return
+	}
+
+	// Default success response (200 OK unless the endpoint sets its own)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(route.successStatus())
+	f.newEncoder(w).Encode(f.wrapEnvelope(route, response))
+}
+
+// acceptsContentType reports whether an Accept header allows contentType
+// Media ranges like */* and text/* match, and ranges with q=0 are excluded
+func acceptsContentType(accept, contentType string) bool {
+	if accept == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	mainType, _, _ := strings.Cut(mediaType, "/")
 
-		// Handle errors
+	for _, accepted := range strings.Split(accept, ",") {
+		acceptedType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
 		if err != nil {
-			f.writeError(w, http.StatusInternalServerError, err.Error(), nil)
-			return
+			continue
+		}
+		if q, ok := params["q"]; ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				continue
+			}
+		}
+		if acceptedType == "*/*" || acceptedType == mediaType || acceptedType == mainType+"/*" {
+			return true
 		}
-
-		// Write response
-		writeResponse(w, response)
 	}
+	return false
 }
 
-// writeResponse writes the response to the HTTP response writer
-func writeResponse[Resp any](w http.ResponseWriter, response Resp) {
-	// Check if response implements Responder interface
-	if responder, ok := any(response).(Responder); ok {
-		responder.WriteResponse(w)
-		return
+// isResponder reports whether response is a Responder that can write itself,
+// i.e. not a nil pointer
+func isResponder(response any) bool {
+	if _, ok := response.(Responder); !ok {
+		return false
 	}
+	value := reflect.ValueOf(response)
+	return value.Kind() != reflect.Pointer || !value.IsNil()
+}
 
-	if statusResponder, ok := any(response).(StatusResponse[Resp]); ok {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(statusResponder.Code)
-		json.NewEncoder(w).Encode(statusResponder.Body)
-		return
+// Warning is a handler error that doesn't fail the request when returned with a Responder,
+// e.g. for partial success: the Responder is written with the message in a Warning header
+// (299 - "row 4 skipped"), and OnRespond hooks receive the warning as the error
+// Returned with any other response, a Warning is handled like any other error
+// Example: return framework.TextResponse(http.StatusOK, "imported 9 of 10"), &framework.Warning{Message: "row 4 skipped"}
+type Warning struct {
+	Message string
+}
+
+// Error implements error
+func (w *Warning) Error() string {
+	return w.Message
+}
+
+// header returns the Warning header value carrying the message
+func (w *Warning) header() string {
+	return "299 - " + strconv.Quote(w.Message)
+}
+
+// errorStatus returns the status code of err if it implements StatusCoder, otherwise fallback
+func errorStatus(err error, fallback int) int {
+	var statusCoder StatusCoder
+	if errors.As(err, &statusCoder) {
+		return statusCoder.StatusCode()
 	}
+	return fallback
+}
 
-	// Default 200 OK response
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+// wrapEnvelope applies the configured response envelope unless the endpoint opted out
+func (f *Framework) wrapEnvelope(route *EndpointSpec, data any) any {
+	if f.envelope == nil || route.DisableEnvelope {
+		return data
+	}
+	return f.envelope(data)
 }
 
 // parseWithPlan parses the request using a pre-computed parser plan
 // This is the OPTIMIZED hot path - uses pre-computed field parsers instead of reflection
 func (f *Framework) parseWithPlan(r *http.Request, reqValue reflect.Value, parser *requestParser) error {
+	// Request types with both Body and Form bind whichever matches the request's body
+	unbound := parser.unboundField(r)
+	skipForm := unbound == "Form"
+	skipBody := unbound == "Body"
+
 	// Iterate through pre-computed field parsers (no reflection needed for tag lookup!)
 	for _, fp := range parser.fieldParsers {
+		if skipForm && fp.sourceType == "form" {
+			continue
+		}
+
 		// Get the actual field value (either top-level or nested)
 		var fieldValue reflect.Value
 		if fp.isNested {
 			// Get the parent struct (Route, Header, Query, or Form)
 			parentField := reqValue.Field(fp.fieldIndex)
 			// Get the nested field within the parent struct
-			fieldValue = parentField.Field(fp.nestedFieldIndex)
+			fieldValue = parentField.FieldByIndex(fp.nestedFieldIndex)
 		} else {
 			// Old behavior for backward compatibility (should not be reached with new system)
 			fieldValue = reqValue.Field(fp.fieldIndex)
 		}
 
+		// Trailers are only available after the body is consumed - handled below
+		if fp.sourceType == "trailer" {
+			continue
+		}
+
 		// Handle file uploads
 		if fp.sourceType == "form" && fp.isFileField {
-			if err := f.parseFileField(r, fieldValue, fp.sourceName); err != nil {
+			parseFile := f.parseFileField
+			if fp.isSlice {
+				parseFile = f.parseFileFields
+			}
+			if err := parseFile(r, fieldValue, fp.sourceName); err != nil {
 				return fmt.Errorf("form '%s': %w", fp.sourceName, err)
 			}
 			continue
 		}
 
-		// Handle query arrays (slices)
-		if fp.isSlice && fp.sourceType == "query" {
-			values := r.URL.Query()[fp.sourceName]
+		// Handle repeated form text values (slices)
+		if fp.isSlice && fp.sourceType == "form" {
+			form, err := f.formValues(r)
+			if err != nil {
+				return fmt.Errorf("form '%s': %w", fp.sourceName, err)
+			}
+			values := form[fp.sourceName]
 			if len(values) > 0 {
 				if err := f.setSliceField(fieldValue, values, fp.setter); err != nil {
 					return fmt.Errorf("%s '%s': %w", fp.sourceType, fp.sourceName, err)
@@ -566,6 +2044,18 @@ func (f *Framework) parseWithPlan(r *http.Request, reqValue reflect.Value, parse
 			continue
 		}
 
+		// Handle query parameters, including arrays
+		if fp.isRawQuery {
+			fieldValue.SetString(r.URL.RawQuery)
+			continue
+		}
+		if fp.sourceType == "query" {
+			if err := f.bindQueryValue(r.URL.Query(), parser, fp, fieldValue); err != nil {
+				return fmt.Errorf("%s '%s': %w", fp.sourceType, fp.sourceName, err)
+			}
+			continue
+		}
+
 		var value string
 		var found bool
 
@@ -577,9 +2067,24 @@ func (f *Framework) parseWithPlan(r *http.Request, reqValue reflect.Value, parse
 		case "route":
 			value = r.PathValue(fp.sourceName)
 			found = value != ""
-		case "query":
-			value = r.URL.Query().Get(fp.sourceName)
-			found = value != ""
+		case "cookie":
+			if cookie, err := r.Cookie(fp.sourceName); err == nil {
+				value = cookie.Value
+				found = value != ""
+			}
+		case "form":
+			form, err := f.formValues(r)
+			if err != nil {
+				return fmt.Errorf("form '%s': %w", fp.sourceName, err)
+			}
+			if values := form[fp.sourceName]; len(values) > 0 {
+				value = values[0]
+				found = true
+			}
+		}
+
+		if value == "" && fp.hasDefault {
+			value, found = fp.defaultValue, true
 		}
 
 		// Set field value using pre-computed setter (no type switch needed!)
@@ -591,15 +2096,59 @@ func (f *Framework) parseWithPlan(r *http.Request, reqValue reflect.Value, parse
 	}
 
 	// Handle body field if present
-	if parser.hasBodyField {
+	if parser.hasBodyField && parser.streamBody {
+		// Hand the stream to the handler without reading it
+		body := r.Body
+		if body == nil {
+			body = http.NoBody
+		}
+		reqValue.Field(parser.bodyFieldIdx).Set(reflect.ValueOf(body))
+	} else if parser.hasBodyField && !skipBody {
 		bodyField := reqValue.Field(parser.bodyFieldIdx)
 		if err := f.parseBody(r, bodyField); err != nil {
 			return fmt.Errorf("body: %w", err)
 		}
 	}
 
-	// Validate the entire request struct
-	if err := f.validator.Struct(reqValue.Interface()); err != nil {
+	// Handle trailer fields once the body has been read
+	if parser.hasTrailerFields {
+		if err := f.parseTrailers(r, reqValue, parser); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unboundField returns "Body" or "Form" when the request type has both and r's body
+// binds the other one, or "" when every field is bound
+func (p *requestParser) unboundField(r *http.Request) string {
+	if !p.hasFormField || !p.hasBodyField {
+		return ""
+	}
+	if isFormRequest(r) {
+		return "Body"
+	}
+	return "Form"
+}
+
+// validateRequest validates the parsed request struct, skipping a Body or Form that
+// wasn't bound from r
+func (f *Framework) validateRequest(r *http.Request, reqValue reflect.Value, parser *requestParser) error {
+	var err error
+	partial, ok := f.validator.(PartialValidator)
+	if unbound := parser.unboundField(r); ok && unbound != "" {
+		err = partial.ValidateExcept(reqValue.Interface(), unbound)
+	} else {
+		err = f.validator.Validate(reqValue.Interface())
+	}
+	if err != nil {
+		// Structured errors from custom engines are reported as they are
+		var structured interface{ ValidationErrors() []ValidationError }
+		if errors.As(err, &structured) {
+			return &validationErrorWrapper{validationErrors: structured.ValidationErrors()}
+		}
+
 		validationErrors := f.formatValidationError(err, parser)
 		if validationErrors != nil {
 			return &validationErrorWrapper{validationErrors: validationErrors}
@@ -610,6 +2159,36 @@ func (f *Framework) parseWithPlan(r *http.Request, reqValue reflect.Value, parse
 	return nil
 }
 
+// parseTrailers binds the Trailer fields from r.Trailer
+// Trailer values are only populated once the body has been read to EOF,
+// so any unread body is drained first. Streamed bodies are rejected with Trailer fields
+// at registration, so this never drains a body the handler still has to read
+func (f *Framework) parseTrailers(r *http.Request, reqValue reflect.Value, parser *requestParser) error {
+	if r.Body != nil {
+		if _, err := io.Copy(io.Discard, r.Body); err != nil {
+			return fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	for _, fp := range parser.fieldParsers {
+		if fp.sourceType != "trailer" {
+			continue
+		}
+
+		value := r.Trailer.Get(fp.sourceName)
+		if value == "" {
+			continue
+		}
+
+		fieldValue := reqValue.Field(fp.fieldIndex).FieldByIndex(fp.nestedFieldIndex)
+		if err := fp.setter(fieldValue, value); err != nil {
+			return fmt.Errorf("%s '%s': %w", fp.sourceType, fp.sourceName, err)
+		}
+	}
+
+	return nil
+}
+
 // parseBody parses the request body
 func (f *Framework) parseBody(r *http.Request, fieldValue reflect.Value) error {
 	if r.Body == nil {
@@ -624,25 +2203,73 @@ func (f *Framework) parseBody(r *http.Request, fieldValue reflect.Value) error {
 	// Create a new instance of the field type
 	newValue := reflect.New(fieldValue.Type())
 
+	// Some clients (e.g. .NET, Excel) prepend a UTF-8 byte order mark
+	bodyReader := skipBOM(r.Body)
+
+	// Bodies of a type with a registered decoder skip JSON decoding
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if decode, ok := f.bodyDecoders[strings.ToLower(mediaType)]; ok {
+		if err := decode(bodyReader, newValue.Interface()); err != nil && err != io.EOF {
+			return fmt.Errorf("invalid %s: %w", mediaType, err)
+		}
+		if err := applyDefaults(newValue.Elem()); err != nil {
+			return err
+		}
+		fieldValue.Set(newValue.Elem())
+		return nil
+	}
+
+	// In lenient mode, coerce string-encoded numbers and booleans before strict decoding
+	if f.lenientBody {
+		coerced, err := coerceJSONBody(bodyReader, fieldValue.Type())
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+		bodyReader = bytes.NewReader(coerced)
+	}
+
 	// Decode JSON body into the new instance
-	decoder := json.NewDecoder(r.Body)
+	decoder := json.NewDecoder(bodyReader)
 	decoder.DisallowUnknownFields()
 
-	if err := decoder.Decode(newValue.Interface()); err != nil {
+	// An empty body leaves the zero value (nil for pointer bodies), so the validator reports
+	// missing required fields and bodies without any are optional
+	if err := decoder.Decode(newValue.Interface()); err != nil && err != io.EOF {
 		return fmt.Errorf("invalid JSON: %w", err)
 	}
 
+	// Fill fields left empty with their declared defaults before validation
+	if err := applyDefaults(newValue.Elem()); err != nil {
+		return err
+	}
+
 	// Set the field to the decoded value
 	fieldValue.Set(newValue.Elem())
 
 	return nil
 }
 
+// utf8BOM is the UTF-8 encoded byte order mark
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// skipBOM returns a reader over body without a leading UTF-8 byte order mark
+// Whitespace before or after the mark is left to the JSON decoder, which skips it
+func skipBOM(body io.Reader) io.Reader {
+	reader := bufio.NewReader(body)
+	if prefix, err := reader.Peek(len(utf8BOM)); err == nil && bytes.Equal(prefix, utf8BOM) {
+		reader.Discard(len(utf8BOM))
+	}
+	return reader
+}
+
 // parseFileField parses a file upload from multipart form data
 func (f *Framework) parseFileField(r *http.Request, fieldValue reflect.Value, formName string) error {
-	// Parse multipart form (32MB max memory)
-	if err := r.ParseMultipartForm(32 << 20); err != nil {
-		return fmt.Errorf("failed to parse multipart form: %w", err)
+	// URL-encoded forms carry no files, so the field stays empty
+	if isURLEncodedForm(r) {
+		return nil
+	}
+	if err := f.parseMultipartForm(r); err != nil {
+		return err
 	}
 
 	file, header, err := r.FormFile(formName)
@@ -662,7 +2289,7 @@ func (f *Framework) parseFileField(r *http.Request, fieldValue reflect.Value, fo
 		Filename: header.Filename,
 		Size:     header.Size,
 		Header:   header,
-		Content:  file,
+		Content:  &onceCloser{ReadCloser: file},
 	}
 
 	// Set the field value
@@ -671,6 +2298,195 @@ func (f *Framework) parseFileField(r *http.Request, fieldValue reflect.Value, fo
 	return nil
 }
 
+// parseFileFields binds every file uploaded under formName, in upload order
+func (f *Framework) parseFileFields(r *http.Request, fieldValue reflect.Value, formName string) error {
+	// URL-encoded forms carry no files, so the field stays empty
+	if isURLEncodedForm(r) {
+		return nil
+	}
+	if err := f.parseMultipartForm(r); err != nil {
+		return err
+	}
+
+	headers := r.MultipartForm.File[formName]
+	if len(headers) == 0 {
+		return nil
+	}
+
+	files := make([]FileField, 0, len(headers))
+	for _, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			for _, opened := range files {
+				opened.Content.Close()
+			}
+			return fmt.Errorf("failed to open form file: %w", err)
+		}
+		files = append(files, FileField{
+			Filename: header.Filename,
+			Size:     header.Size,
+			Header:   header,
+			Content:  &onceCloser{ReadCloser: file},
+		})
+	}
+
+	fieldValue.Set(reflect.ValueOf(files))
+	return nil
+}
+
+// formValues returns the text values of the request form
+// URL-encoded bodies are read with r.ParseForm, multipart bodies with parseMultipartForm
+func (f *Framework) formValues(r *http.Request) (url.Values, error) {
+	if isURLEncodedForm(r) {
+		if err := r.ParseForm(); err != nil {
+			return nil, fmt.Errorf("failed to parse form: %w", err)
+		}
+		return r.PostForm, nil
+	}
+
+	if err := f.parseMultipartForm(r); err != nil {
+		return nil, err
+	}
+	return r.MultipartForm.Value, nil
+}
+
+// isFormRequest reports whether r has a multipart or URL-encoded form body
+func isFormRequest(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && (strings.HasPrefix(mediaType, "multipart/") || mediaType == "application/x-www-form-urlencoded")
+}
+
+// isURLEncodedForm reports whether r has an application/x-www-form-urlencoded body
+func isURLEncodedForm(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/x-www-form-urlencoded"
+}
+
+// parseMultipartForm parses the multipart form and enforces the configured file count limit
+// It is safe to call multiple times - the form is only parsed once per request
+// multipart/mixed bodies are supported as well as multipart/form-data
+func (f *Framework) parseMultipartForm(r *http.Request) error {
+	if isMultipartMixed(r) {
+		if r.MultipartForm != nil {
+			return nil
+		}
+		return f.parseMultipartMixed(r, f.multipartMemory())
+	}
+
+	// File parts are counted as they stream in, so the limit stops oversized
+	// requests before their files are buffered or written to disk
+	if f.maxMultipartFiles > 0 {
+		if r.MultipartForm != nil {
+			return nil
+		}
+		return f.parseLimitedMultipartForm(r, f.multipartMemory())
+	}
+
+	// Parts beyond the memory limit are stored in temporary files
+	if err := r.ParseMultipartForm(f.multipartMemory()); err != nil {
+		return fmt.Errorf("failed to parse multipart form: %w", err)
+	}
+
+	return nil
+}
+
+// bindQueryValue binds the query parameter described by fp into fieldValue
+// Slices accept repeated parameters (?id=1&id=2) or index notation (?id[0]=1&id[1]=2)
+// Catch-all maps receive the first value of every parameter not declared in parser
+func (f *Framework) bindQueryValue(query url.Values, parser *requestParser, fp fieldParser, fieldValue reflect.Value) error {
+	if fp.isCatchAll {
+		rest := make(map[string]string)
+		for name, values := range query {
+			// Skip declared parameters, including their index notation (name[0])
+			baseName, _, _ := strings.Cut(name, "[")
+			if parser.queryNames[name] || parser.queryNames[baseName] || len(values) == 0 {
+				continue
+			}
+			rest[name] = values[0]
+		}
+		fieldValue.Set(reflect.ValueOf(rest))
+		return nil
+	}
+
+	if fp.isSlice {
+		values := query[fp.sourceName]
+		if len(values) == 0 {
+			values = indexedValues(query, fp.sourceName)
+		}
+		if len(values) == 0 && fp.hasDefault {
+			values = splitDefault(fp.defaultValue, true)
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		return f.setSliceField(fieldValue, values, fp.setter)
+	}
+
+	value := query.Get(fp.sourceName)
+	if value == "" && fp.hasDefault {
+		value = fp.defaultValue
+	}
+	if value != "" {
+		return fp.setter(fieldValue, value)
+	}
+	return nil
+}
+
+// DecodeQuery binds query values into dest, which must be a pointer to a struct
+// It uses the same setters and json tag names as a request's Query struct,
+// so it can parse query strings outside of a request (e.g. in background jobs)
+// No validation is performed
+func DecodeQuery(values url.Values, dest any) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Pointer || destValue.IsNil() || destValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("dest must be a non-nil pointer to a struct, got %T", dest)
+	}
+	structValue := destValue.Elem()
+
+	parser := &requestParser{}
+	parseNestedStruct(parser, structValue.Type(), 0, "query")
+
+	f := parseRequestFramework()
+	for _, fp := range parser.fieldParsers {
+		if err := f.bindQueryValue(values, parser, fp, structValue.FieldByIndex(fp.nestedFieldIndex)); err != nil {
+			return fmt.Errorf("%s '%s': %w", fp.sourceType, fp.sourceName, err)
+		}
+	}
+	return nil
+}
+
+// indexedValues collects values sent with index notation (name[0], name[1], ...)
+// ordered by index
+func indexedValues(query url.Values, name string) []string {
+	type indexedValue struct {
+		index int
+		value string
+	}
+
+	var indexed []indexedValue
+	prefix := name + "["
+	for key, values := range query {
+		if !strings.HasPrefix(key, prefix) || !strings.HasSuffix(key, "]") || len(values) == 0 {
+			continue
+		}
+		index, err := strconv.Atoi(key[len(prefix) : len(key)-1])
+		if err != nil || index < 0 {
+			continue
+		}
+		indexed = append(indexed, indexedValue{index: index, value: values[0]})
+	}
+
+	slices.SortFunc(indexed, func(a, b indexedValue) int {
+		return a.index - b.index
+	})
+
+	result := make([]string, len(indexed))
+	for i, iv := range indexed {
+		result[i] = iv.value
+	}
+	return result
+}
+
 // setSliceField sets a slice field from multiple string values
 func (f *Framework) setSliceField(fieldValue reflect.Value, values []string, setter func(reflect.Value, string) error) error {
 	// Create a new slice of the appropriate type
@@ -706,10 +2522,14 @@ func (f *Framework) formatValidationError(err error, parser *requestParser) []Va
 		for _, fp := range parser.fieldParsers {
 			parentFieldName := parser.requestType.Field(fp.fieldIndex).Name
 			if fp.isNested {
-				// Get the nested struct type
-				parentType := parser.requestType.Field(fp.fieldIndex).Type
-				nestedFieldName := parentType.Field(fp.nestedFieldIndex).Name
-				structPath := parentFieldName + "." + nestedFieldName
+				// Build the struct path through any embedded structs (e.g. "Query.PageRequest.Page")
+				structPath := parentFieldName
+				structType := parser.requestType.Field(fp.fieldIndex).Type
+				for _, index := range fp.nestedFieldIndex {
+					field := structType.Field(index)
+					structPath += "." + field.Name
+					structType = field.Type
+				}
 				fieldTagMap[structPath] = struct {
 					tagName    string
 					sourceType string
@@ -740,21 +2560,27 @@ func (f *Framework) formatValidationError(err error, parser *requestParser) []Va
 		fieldErrorMap := make(map[fieldKey][]string)
 
 		for _, e := range validationErrs {
-			errorMsg := fmt.Sprintf("failed validation: %s", e.Tag())
-
 			// Parse the namespace to determine the field path
 			namespace := e.StructNamespace()
 			parts := splitFieldPath(namespace)
 
+			// Prefer the field's own errmsg tag over the generic message
+			errorMsg := fmt.Sprintf("failed validation: %s", e.Tag())
+			if field, ok := lookupStructField(parser.requestType, parts[1:]); ok {
+				if msg := field.Tag.Get("errmsg"); msg != "" {
+					errorMsg = msg
+				}
+			}
+
 			var actualFieldName string
 			var sourceType string
 
 			// Format: "RequestName.ParentField.NestedField" (3 parts) = nested field
+			// Format: "RequestName.ParentField.Embedded.NestedField" (4 parts) = embedded nested field
 			// Format: "RequestName.Body.FieldName" (3+ parts) = body field
 			if len(parts) >= 3 {
 				parentFieldName := parts[1]
-				nestedFieldName := parts[2]
-				structPath := parentFieldName + "." + nestedFieldName
+				structPath := strings.Join(parts[1:], ".")
 
 				// Check if this is a Body field
 				if parser.hasBodyField {
@@ -803,6 +2629,34 @@ func (f *Framework) formatValidationError(err error, parser *requestParser) []Va
 	return nil
 }
 
+// lookupStructField resolves a validator struct path (e.g. ["Body", "Items[0]", "Name"])
+// to the struct field it names, descending through pointers, slices and maps
+func lookupStructField(structType reflect.Type, path []string) (reflect.StructField, bool) {
+	var field reflect.StructField
+	for _, name := range path {
+		for structType.Kind() == reflect.Pointer || structType.Kind() == reflect.Slice ||
+			structType.Kind() == reflect.Array || structType.Kind() == reflect.Map {
+			structType = structType.Elem()
+		}
+		if structType.Kind() != reflect.Struct {
+			return reflect.StructField{}, false
+		}
+
+		// Strip any index suffix added for slice and map elements
+		if i := strings.IndexByte(name, '['); i >= 0 {
+			name = name[:i]
+		}
+
+		var ok bool
+		field, ok = structType.FieldByName(name)
+		if !ok {
+			return reflect.StructField{}, false
+		}
+		structType = field.Type
+	}
+	return field, len(path) > 0
+}
+
 // splitFieldPath splits a field namespace path (e.g., "CreateUserRequest.Body.Name")
 func splitFieldPath(namespace string) []string {
 	parts := []string{}
@@ -825,9 +2679,20 @@ func splitFieldPath(namespace string) []string {
 
 // writeError writes an error response
 func (f *Framework) writeError(w http.ResponseWriter, statusCode int, message string, details map[string]string) {
+	if f.problemDetails {
+		f.writeProblem(w, ProblemDetails{
+			Type:    "about:blank",
+			Title:   http.StatusText(statusCode),
+			Status:  statusCode,
+			Detail:  message,
+			Details: details,
+		})
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(ErrorResponse{
+	f.newEncoder(w).Encode(ErrorResponse{
 		Error:   message,
 		Details: details,
 	})
@@ -835,20 +2700,208 @@ func (f *Framework) writeError(w http.ResponseWriter, statusCode int, message st
 
 // writeValidationError writes a validation error response
 func (f *Framework) writeValidationError(w http.ResponseWriter, statusCode int, validationErrors []ValidationError) {
+	if f.problemDetails {
+		f.writeProblem(w, ProblemDetails{
+			Type:   "about:blank",
+			Title:  http.StatusText(statusCode),
+			Status: statusCode,
+			Detail: "validation failed",
+			Fields: validationErrors,
+		})
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(ValidationErrorResponse{
+	f.newEncoder(w).Encode(ValidationErrorResponse{
 		Error:  "validation failed",
 		Fields: validationErrors,
 	})
 }
 
+// writeProblem writes an RFC 7807 problem details response
+func (f *Framework) writeProblem(w http.ResponseWriter, problem ProblemDetails) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	f.newEncoder(w).Encode(problem)
+}
+
 // ServeHTTP implements http.Handler
+// Requests to a registered path with an unregistered method get a JSON 405 Method Not
+// Allowed with an Allow header, and requests matching no path go to the not-found handler.
+// This needs a mux that reports its matches like *http.ServeMux does; other muxes handle
+// unmatched requests themselves
 func (f *Framework) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if mux, ok := f.mux.(matchingMux); ok {
+		if _, pattern := mux.Handler(r); pattern == "" {
+			if allowed := f.matchingMethods(mux, r); len(allowed) > 0 {
+				w.Header().Set("Allow", strings.Join(allowed, ", "))
+				if fallback := f.optionsFallback(mux, r, allowed[0]); fallback != nil {
+					fallback.ServeHTTP(w, r)
+					return
+				}
+				f.writeError(w, http.StatusMethodNotAllowed, "method not allowed", nil)
+				return
+			}
+			f.serveNotFound(w, r)
+			return
+		}
+	}
 	f.mux.ServeHTTP(w, r)
 }
 
+// SetNotFoundHandler sets the handler for requests that match no route
+// The default responds 404 with the framework's JSON error shape; nil restores it
+func (f *Framework) SetNotFoundHandler(handler http.Handler) {
+	f.notFoundHandler = handler
+}
+
+// serveNotFound responds to a request that matches no route
+func (f *Framework) serveNotFound(w http.ResponseWriter, r *http.Request) {
+	if f.notFoundHandler != nil {
+		f.notFoundHandler.ServeHTTP(w, r)
+		return
+	}
+	f.writeError(w, http.StatusNotFound, "not found", nil)
+}
+
+// matchingMux is a Mux that reports the pattern matching a request, like *http.ServeMux
+// An empty pattern means no route matches the request's method and path
+type matchingMux interface {
+	Handler(r *http.Request) (h http.Handler, pattern string)
+}
+
+// optionsFallback returns the registered 405 handler for an OPTIONS request whose path
+// is served by method, or nil for other requests
+func (f *Framework) optionsFallback(mux matchingMux, r *http.Request, method string) http.Handler {
+	if r.Method != http.MethodOptions {
+		return nil
+	}
+	probe := r.Clone(r.Context())
+	probe.Method = method
+	_, pattern := mux.Handler(probe)
+	_, path, _ := strings.Cut(pattern, " ")
+	return f.optionsFallbacks[path]
+}
+
+// matchingMethods returns the sorted registered methods with a route matching r's path
+func (f *Framework) matchingMethods(mux matchingMux, r *http.Request) []string {
+	methods := []string{http.MethodOptions} // registered per path with auto-OPTIONS
+	for _, endpoint := range f.endpoints {
+		methods = append(methods, endpoint.Method)
+	}
+	// The mux serves HEAD requests with GET handlers
+	if slices.Contains(methods, http.MethodGet) {
+		methods = append(methods, http.MethodHead)
+	}
+	slices.Sort(methods)
+	methods = slices.Compact(methods)
+
+	allowed := make([]string, 0, len(methods))
+	probe := r.Clone(r.Context())
+	for _, method := range methods {
+		probe.Method = method
+		if _, pattern := mux.Handler(probe); pattern != "" {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
 // GetEndpoints returns all registered endpoints
 func (f *Framework) GetEndpoints() []*EndpointSpec {
 	return f.endpoints
 }
+
+// MiddlewareNames returns the function names of the endpoint's resolved middlewares,
+// outermost first, for debugging middleware order
+// Middlewares created by constructors report the closure name, e.g. "middleware.Timeout.func1"
+func MiddlewareNames(e *EndpointSpec) []string {
+	names := make([]string, 0, len(e.AllMiddlewares))
+	for _, middleware := range e.AllMiddlewares {
+		name := "unknown"
+		if fn := runtime.FuncForPC(reflect.ValueOf(middleware).Pointer()); fn != nil {
+			name = fn.Name()
+			// Trim the package path, keeping the package name
+			if i := strings.LastIndex(name, "/"); i >= 0 {
+				name = name[i+1:]
+			}
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// RouteInfo describes a registered route
+type RouteInfo struct {
+	Method      string
+	Path        string
+	Summary     string
+	Middlewares int
+}
+
+// Routes returns the routing table sorted by path then method
+// Useful for diagnosing registration issues
+func (f *Framework) Routes() []RouteInfo {
+	routes := make([]RouteInfo, 0, len(f.endpoints))
+	for _, endpoint := range f.endpoints {
+		routes = append(routes, RouteInfo{
+			Method:      endpoint.Method,
+			Path:        endpoint.FullPath,
+			Summary:     endpoint.Summary,
+			Middlewares: len(endpoint.AllMiddlewares),
+		})
+	}
+
+	slices.SortStableFunc(routes, func(a, b RouteInfo) int {
+		if c := strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Method, b.Method)
+	})
+	return routes
+}
+
+// URL builds the path of the endpoint registered for method and pattern by substituting
+// its {name} wildcards with params. Values are path-escaped, except that a trailing
+// {name...} wildcard keeps its slashes. Every wildcard must have a non-empty value
+// Example: app.URL("GET", "/users/{id}", map[string]string{"id": "42"}) returns "/users/42"
+func (f *Framework) URL(method, pattern string, params map[string]string) (string, error) {
+	registered := slices.ContainsFunc(f.endpoints, func(e *EndpointSpec) bool {
+		return e.Method == method && e.FullPath == pattern
+	})
+	if !registered {
+		return "", fmt.Errorf("no endpoint registered for %s %s", method, pattern)
+	}
+
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+			continue
+		}
+		if segment == "{$}" {
+			segments[i] = ""
+			continue
+		}
+
+		name := segment[1 : len(segment)-1]
+		remainder := strings.HasSuffix(name, "...")
+		name = strings.TrimSuffix(name, "...")
+
+		value := params[name]
+		if value == "" {
+			return "", fmt.Errorf("missing route parameter %q for %s", name, pattern)
+		}
+
+		if remainder {
+			parts := strings.Split(value, "/")
+			for j, part := range parts {
+				parts[j] = url.PathEscape(part)
+			}
+			segments[i] = strings.Join(parts, "/")
+		} else {
+			segments[i] = url.PathEscape(value)
+		}
+	}
+	return strings.Join(segments, "/"), nil
+}
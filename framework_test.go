@@ -0,0 +1,748 @@
+package framework_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RottenNinja-Go/framework"
+	"github.com/RottenNinja-Go/framework/handler"
+)
+
+// serve sends r to app and returns the recorded response
+func serve(app http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	return w
+}
+
+// jsonRequest builds a request with a JSON body
+func jsonRequest(method, target, body string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	return r
+}
+
+// multipartFile is a file part of a multipart test request
+type multipartFile struct {
+	field, filename, content string
+}
+
+// multipartRequest builds a multipart/form-data request from text fields and files
+func multipartRequest(t *testing.T, target string, fields map[string][]string, files []multipartFile) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, values := range fields {
+		for _, value := range values {
+			if err := writer.WriteField(name, value); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for _, file := range files {
+		part, err := writer.CreateFormFile(file.field, file.filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(part, file.content)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, target, &body)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+	return r
+}
+
+// expectStatus fails the test if the response doesn't have the wanted status code
+func expectStatus(t *testing.T, w *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if w.Code != want {
+		t.Fatalf("status = %d, want %d (body: %s)", w.Code, want, w.Body.String())
+	}
+}
+
+type UploadRequest struct {
+	Form struct {
+		Files []framework.FileField `json:"files"`
+	}
+}
+
+type UploadResponse struct {
+	Count int `json:"count"`
+}
+
+func TestMaxMultipartFiles(t *testing.T) {
+	app := framework.New()
+	app.SetMaxMultipartFiles(2)
+	handler.POST(app, "/upload", func(ctx context.Context, req UploadRequest) (UploadResponse, error) {
+		return UploadResponse{Count: len(req.Form.Files)}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	files := func(n int) []multipartFile {
+		var files []multipartFile
+		for i := range n {
+			files = append(files, multipartFile{"files", fmt.Sprintf("f%d.txt", i), "content"})
+		}
+		return files
+	}
+
+	w := serve(app, multipartRequest(t, "/upload", nil, files(2)))
+	expectStatus(t, w, http.StatusOK)
+
+	w = serve(app, multipartRequest(t, "/upload", nil, files(3)))
+	expectStatus(t, w, http.StatusBadRequest)
+	if !strings.Contains(w.Body.String(), "too many files") {
+		t.Errorf("body = %s, want a too many files error", w.Body.String())
+	}
+}
+
+// countingReader counts the bytes read from r
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestMaxMultipartFilesStopsReading(t *testing.T) {
+	app := framework.New()
+	app.SetMaxMultipartFiles(1)
+	handler.POST(app, "/upload", func(ctx context.Context, req UploadRequest) (UploadResponse, error) {
+		return UploadResponse{Count: len(req.Form.Files)}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	var files []multipartFile
+	for i := range 100 {
+		files = append(files, multipartFile{"files", fmt.Sprintf("f%d.txt", i), strings.Repeat("x", 10<<10)})
+	}
+	r := multipartRequest(t, "/upload", nil, files)
+	size := r.ContentLength
+	body := &countingReader{r: r.Body}
+	r.Body = io.NopCloser(body)
+
+	w := serve(app, r)
+	expectStatus(t, w, http.StatusBadRequest)
+	if int64(body.n) >= size/2 {
+		t.Errorf("read %d of %d bytes, want the request rejected early", body.n, size)
+	}
+}
+
+type AvatarRequest struct {
+	Form struct {
+		Avatar framework.FileField `json:"avatar"`
+	}
+}
+
+func TestFileFieldBytes(t *testing.T) {
+	var content []byte
+	var closeErr error
+	app := framework.New()
+	handler.POST(app, "/avatar", func(ctx context.Context, req AvatarRequest) (UploadResponse, error) {
+		var err error
+		if content, err = req.Form.Avatar.Bytes(); err != nil {
+			return UploadResponse{}, err
+		}
+		// Closing again after Bytes must be safe
+		closeErr = req.Form.Avatar.Content.Close()
+		return UploadResponse{Count: 1}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	w := serve(app, multipartRequest(t, "/avatar", nil, []multipartFile{{"avatar", "a.png", "image data"}}))
+	expectStatus(t, w, http.StatusOK)
+	if string(content) != "image data" {
+		t.Errorf("Bytes() = %q, want %q", content, "image data")
+	}
+	if closeErr != nil {
+		t.Errorf("second Close() = %v, want nil", closeErr)
+	}
+
+	if _, err := (framework.FileField{}).Bytes(); err == nil {
+		t.Error("Bytes() on a file without content succeeded")
+	}
+}
+
+func TestFileFieldSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "avatar.png")
+	app := framework.New()
+	handler.POST(app, "/avatar", func(ctx context.Context, req AvatarRequest) (UploadResponse, error) {
+		if err := req.Form.Avatar.Save(path); err != nil {
+			return UploadResponse{}, err
+		}
+		return UploadResponse{Count: 1}, req.Form.Avatar.Content.Close()
+	}, func(eo handler.EndpointOptions) {})
+
+	w := serve(app, multipartRequest(t, "/avatar", nil, []multipartFile{{"avatar", "a.png", "image data"}}))
+	expectStatus(t, w, http.StatusOK)
+	saved, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(saved) != "image data" {
+		t.Errorf("saved content = %q, want %q", saved, "image data")
+	}
+}
+
+type SecretRequest struct {
+	Header struct {
+		APIKey string `json:"X-API-Key" validate:"required,len=32"`
+	}
+	Query struct {
+		Verbose bool `json:"verbose"`
+	}
+}
+
+func TestRequestSchema(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/secret", func(ctx context.Context, req SecretRequest) (UploadResponse, error) {
+		return UploadResponse{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	fields := app.GetEndpoints()[0].RequestSchema()
+	if len(fields) != 2 {
+		t.Fatalf("RequestSchema() = %+v, want 2 fields", fields)
+	}
+	for _, field := range fields {
+		switch field.Name {
+		case "X-API-Key":
+			if field.SourceType != "header" || !field.Required || field.Validate != "required,len=32" {
+				t.Errorf("X-API-Key = %+v, want a required header with its validate tag", field)
+			}
+		case "verbose":
+			if field.SourceType != "query" || field.Required {
+				t.Errorf("verbose = %+v, want an optional query parameter", field)
+			}
+		default:
+			t.Errorf("unexpected field %+v", field)
+		}
+	}
+}
+
+type TaggedUploadRequest struct {
+	Form struct {
+		Tags    []string `json:"tags"`
+		Ratings []int    `json:"ratings"`
+	}
+}
+
+type TaggedUploadResponse struct {
+	Tags    []string `json:"tags"`
+	Ratings []int    `json:"ratings"`
+}
+
+func TestRepeatedFormValues(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/tags", func(ctx context.Context, req TaggedUploadRequest) (TaggedUploadResponse, error) {
+		return TaggedUploadResponse{Tags: req.Form.Tags, Ratings: req.Form.Ratings}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	w := serve(app, multipartRequest(t, "/tags", map[string][]string{
+		"tags":    {"go", "http", "openapi"},
+		"ratings": {"5", "3"},
+	}, nil))
+	expectStatus(t, w, http.StatusOK)
+	if body := w.Body.String(); body != `{"tags":["go","http","openapi"],"ratings":[5,3]}`+"\n" {
+		t.Errorf("body = %s, want the repeated values bound in order", body)
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	app := framework.New()
+	handler.PATCH(app, "/users/{id}", okHandler, func(eo handler.EndpointOptions) {})
+	handler.GET(app, "/users/{id}", okHandler, func(eo handler.EndpointOptions) {
+		eo.SetSummary("Get user")
+	})
+	handler.POST(app, "/users", okHandler, func(eo handler.EndpointOptions) {})
+	admin := app.Group("/admin")
+	admin.Use(requireToken)
+	handler.DELETE(admin, "/users/{id}", okHandler, func(eo handler.EndpointOptions) {})
+
+	want := []framework.RouteInfo{
+		{Method: http.MethodDelete, Path: "/admin/users/{id}", Middlewares: 1},
+		{Method: http.MethodPost, Path: "/users"},
+		{Method: http.MethodGet, Path: "/users/{id}", Summary: "Get user"},
+		{Method: http.MethodPatch, Path: "/users/{id}"},
+	}
+	routes := app.Routes()
+	if len(routes) != len(want) {
+		t.Fatalf("Routes() = %+v, want %+v", routes, want)
+	}
+	for i := range want {
+		if routes[i] != want[i] {
+			t.Errorf("Routes()[%d] = %+v, want %+v", i, routes[i], want[i])
+		}
+	}
+}
+
+type MixedRequest struct {
+	Form struct {
+		Metadata string              `json:"metadata" validate:"required"`
+		Document framework.FileField `json:"1" validate:"required"`
+	}
+}
+
+type MixedResponse struct {
+	Metadata string `json:"metadata"`
+	Filename string `json:"filename"`
+	Content  string `json:"content"`
+}
+
+func TestMultipartMixed(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/documents", func(ctx context.Context, req MixedRequest) (MixedResponse, error) {
+		content, err := req.Form.Document.Bytes()
+		if err != nil {
+			return MixedResponse{}, err
+		}
+		return MixedResponse{
+			Metadata: req.Form.Metadata,
+			Filename: req.Form.Document.Filename,
+			Content:  string(content),
+		}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"application/json"},
+		"Content-Id":   {"<metadata>"},
+	})
+	io.WriteString(part, `{"pages":2}`)
+	part, _ = writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":        {"application/pdf"},
+		"Content-Disposition": {`attachment; filename="doc.pdf"`},
+	})
+	io.WriteString(part, "%PDF-1.7")
+	writer.Close()
+
+	r := httptest.NewRequest(http.MethodPost, "/documents", &body)
+	r.Header.Set("Content-Type", "multipart/mixed; boundary="+writer.Boundary())
+	w := serve(app, r)
+	expectStatus(t, w, http.StatusOK)
+
+	var got MixedResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := MixedResponse{Metadata: `{"pages":2}`, Filename: "doc.pdf", Content: "%PDF-1.7"}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+// stubMux records registered patterns and the requests it serves
+type stubMux struct {
+	patterns []string
+	served   []string
+}
+
+func (m *stubMux) Handle(pattern string, h http.Handler) {
+	m.patterns = append(m.patterns, pattern)
+}
+
+func (m *stubMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.served = append(m.served, r.URL.Path)
+}
+
+func TestNewWithMux(t *testing.T) {
+	mux := &stubMux{}
+	app := framework.NewWithMux(mux)
+	handler.GET(app, "/users/{id}", okHandler, func(eo handler.EndpointOptions) {})
+	handler.POST(app, "/users", okHandler, func(eo handler.EndpointOptions) {})
+
+	for _, want := range []string{"GET /users/{id}", "POST /users"} {
+		if !slices.Contains(mux.patterns, want) {
+			t.Errorf("patterns = %q, want %q registered", mux.patterns, want)
+		}
+	}
+
+	serve(app, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	if len(mux.served) != 1 || mux.served[0] != "/users/1" {
+		t.Errorf("served = %q, want the request dispatched to the mux", mux.served)
+	}
+}
+
+type SleepRequest struct {
+	Query struct {
+		Millis int  `json:"ms"`
+		Ignore bool `json:"ignore"`
+	}
+}
+
+// sleep waits for the requested milliseconds, returning early with the context error when
+// the deadline passes unless told to ignore it
+func sleep(ctx context.Context, req SleepRequest) (UploadResponse, error) {
+	duration := time.Duration(req.Query.Millis) * time.Millisecond
+	select {
+	case <-time.After(duration):
+	case <-ctx.Done():
+		if !req.Query.Ignore {
+			return UploadResponse{}, ctx.Err()
+		}
+		time.Sleep(duration)
+	}
+	return UploadResponse{Count: 1}, nil
+}
+
+func TestEndpointTimeout(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/fast", sleep, func(eo handler.EndpointOptions) {
+		eo.SetTimeout(20 * time.Millisecond)
+	})
+	handler.GET(app, "/slow", sleep, func(eo handler.EndpointOptions) {
+		eo.SetTimeout(time.Second)
+	})
+
+	tests := []struct {
+		target string
+		want   int
+	}{
+		{"/fast?ms=1", http.StatusOK},
+		{"/fast?ms=100", http.StatusServiceUnavailable},
+		{"/slow?ms=100", http.StatusOK},
+		// A handler succeeding after its deadline still timed out
+		{"/fast?ms=50&ignore=true", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		w := serve(app, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (body: %s)", tt.target, w.Code, tt.want, w.Body.String())
+		}
+		if tt.want == http.StatusServiceUnavailable && !strings.Contains(w.Body.String(), "request timed out") {
+			t.Errorf("%s: body = %s, want only the timeout error", tt.target, w.Body.String())
+		}
+	}
+}
+
+type SessionRequest struct {
+	Cookie struct {
+		Session  string `json:"session" validate:"required"`
+		Visits   int    `json:"visits"`
+		Returner bool   `json:"returner"`
+	}
+}
+
+type SessionResponse struct {
+	Session  string `json:"session"`
+	Visits   int    `json:"visits"`
+	Returner bool   `json:"returner"`
+}
+
+func TestCookieBinding(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/session", func(ctx context.Context, req SessionRequest) (SessionResponse, error) {
+		c := req.Cookie
+		return SessionResponse{c.Session, c.Visits, c.Returner}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	r := httptest.NewRequest(http.MethodGet, "/session", nil)
+	r.AddCookie(&http.Cookie{Name: "session", Value: "abc123"})
+	r.AddCookie(&http.Cookie{Name: "visits", Value: "7"})
+	r.AddCookie(&http.Cookie{Name: "returner", Value: "true"})
+	w := serve(app, r)
+	expectStatus(t, w, http.StatusOK)
+	if want := `{"session":"abc123","visits":7,"returner":true}` + "\n"; w.Body.String() != want {
+		t.Errorf("body = %s, want %s", w.Body.String(), want)
+	}
+
+	w = serve(app, httptest.NewRequest(http.MethodGet, "/session", nil))
+	expectStatus(t, w, http.StatusBadRequest)
+	var resp framework.ValidationErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Fields) != 1 || resp.Fields[0].Field != "session" || resp.Fields[0].SourceType != "cookie" {
+		t.Errorf("fields = %+v, want the missing session cookie", resp.Fields)
+	}
+}
+
+type PhotoRequest struct {
+	Form struct {
+		Photo    framework.FileField `json:"photo" validate:"required"`
+		Caption  string              `json:"caption" validate:"required"`
+		Width    int                 `json:"width"`
+		Public   bool                `json:"public"`
+		Rating   float64             `json:"rating"`
+		Keywords []string            `json:"keywords"`
+	}
+}
+
+type PhotoResponse struct {
+	Filename string   `json:"filename"`
+	Caption  string   `json:"caption"`
+	Width    int      `json:"width"`
+	Public   bool     `json:"public"`
+	Rating   float64  `json:"rating"`
+	Keywords []string `json:"keywords"`
+}
+
+func TestFormFieldsWithFile(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/photos", func(ctx context.Context, req PhotoRequest) (PhotoResponse, error) {
+		f := req.Form
+		return PhotoResponse{f.Photo.Filename, f.Caption, f.Width, f.Public, f.Rating, f.Keywords}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	w := serve(app, multipartRequest(t, "/photos", map[string][]string{
+		"caption":  {"Sunset"},
+		"width":    {"1920"},
+		"public":   {"true"},
+		"rating":   {"4.5"},
+		"keywords": {"sky", "sea"},
+	}, []multipartFile{{"photo", "sunset.jpg", "jpeg"}}))
+	expectStatus(t, w, http.StatusOK)
+	want := `{"filename":"sunset.jpg","caption":"Sunset","width":1920,"public":true,"rating":4.5,"keywords":["sky","sea"]}` + "\n"
+	if w.Body.String() != want {
+		t.Errorf("body = %s, want %s", w.Body.String(), want)
+	}
+
+	// Scalar fields are validated like files
+	w = serve(app, multipartRequest(t, "/photos", nil, []multipartFile{{"photo", "sunset.jpg", "jpeg"}}))
+	expectStatus(t, w, http.StatusBadRequest)
+	if !strings.Contains(w.Body.String(), "caption") {
+		t.Errorf("body = %s, want the missing caption reported", w.Body.String())
+	}
+
+	// Malformed scalars are rejected
+	w = serve(app, multipartRequest(t, "/photos", map[string][]string{
+		"caption": {"Sunset"},
+		"width":   {"wide"},
+	}, []multipartFile{{"photo", "sunset.jpg", "jpeg"}}))
+	expectStatus(t, w, http.StatusBadRequest)
+}
+
+type SubscribeRequest struct {
+	Form struct {
+		Email  string   `json:"email" validate:"required,email"`
+		Weekly bool     `json:"weekly"`
+		Limit  int      `json:"limit"`
+		Topics []string `json:"topics"`
+	}
+}
+
+type SubscribeResponse struct {
+	Email  string   `json:"email"`
+	Weekly bool     `json:"weekly"`
+	Limit  int      `json:"limit"`
+	Topics []string `json:"topics"`
+}
+
+func TestURLEncodedForm(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/subscribe", func(ctx context.Context, req SubscribeRequest) (SubscribeResponse, error) {
+		f := req.Form
+		return SubscribeResponse{f.Email, f.Weekly, f.Limit, f.Topics}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	form := url.Values{
+		"email":  {"ada@example.com"},
+		"weekly": {"true"},
+		"limit":  {"3"},
+		"topics": {"go", "http"},
+	}
+	r := httptest.NewRequest(http.MethodPost, "/subscribe", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := serve(app, r)
+	expectStatus(t, w, http.StatusOK)
+	want := `{"email":"ada@example.com","weekly":true,"limit":3,"topics":["go","http"]}` + "\n"
+	if w.Body.String() != want {
+		t.Errorf("body = %s, want %s", w.Body.String(), want)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/subscribe", strings.NewReader("email=not-an-email"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	expectStatus(t, serve(app, r), http.StatusBadRequest)
+}
+
+type GalleryRequest struct {
+	Form struct {
+		Photos []framework.FileField `json:"photos" validate:"required"`
+	}
+}
+
+type GalleryResponse struct {
+	Filenames []string `json:"filenames"`
+	Contents  []string `json:"contents"`
+}
+
+func TestMultipleFileUploads(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/gallery", func(ctx context.Context, req GalleryRequest) (GalleryResponse, error) {
+		var resp GalleryResponse
+		for _, photo := range req.Form.Photos {
+			content, err := photo.Bytes()
+			if err != nil {
+				return GalleryResponse{}, err
+			}
+			resp.Filenames = append(resp.Filenames, photo.Filename)
+			resp.Contents = append(resp.Contents, string(content))
+		}
+		return resp, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	w := serve(app, multipartRequest(t, "/gallery", nil, []multipartFile{
+		{"photos", "first.jpg", "one"},
+		{"photos", "second.jpg", "two"},
+	}))
+	expectStatus(t, w, http.StatusOK)
+	want := `{"filenames":["first.jpg","second.jpg"],"contents":["one","two"]}` + "\n"
+	if w.Body.String() != want {
+		t.Errorf("body = %s, want %s", w.Body.String(), want)
+	}
+
+	expectStatus(t, serve(app, multipartRequest(t, "/gallery", nil, nil)), http.StatusBadRequest)
+}
+
+type PointerGalleryRequest struct {
+	Form struct {
+		Photos []*framework.FileField `json:"photos"`
+	}
+}
+
+func TestFileFieldPointersRejected(t *testing.T) {
+	app := framework.New()
+	message := registrationPanic(func() {
+		handler.POST(app, "/gallery", func(ctx context.Context, req PointerGalleryRequest) (UploadResponse, error) {
+			return UploadResponse{}, nil
+		}, func(eo handler.EndpointOptions) {})
+	})
+	if !strings.Contains(message, "photos") || !strings.Contains(message, "[]framework.FileField") {
+		t.Errorf("panic = %q, want the photos field and the supported types", message)
+	}
+}
+
+type StoredUploadResponse struct {
+	Size   int64 `json:"size"`
+	OnDisk bool  `json:"on_disk"`
+}
+
+func TestMultipartLimits(t *testing.T) {
+	leftover := multipartTempDir(t)
+	app := framework.New()
+	app.SetMaxMultipartMemory(1 << 10)
+	app.SetMaxRequestSize(64 << 10)
+	handler.POST(app, "/avatar", func(ctx context.Context, req AvatarRequest) (StoredUploadResponse, error) {
+		defer req.Form.Avatar.Content.Close()
+		// Parts beyond the memory limit are opened from temporary files
+		file, err := req.Form.Avatar.Header.Open()
+		if err != nil {
+			return StoredUploadResponse{}, err
+		}
+		defer file.Close()
+		_, onDisk := file.(*os.File)
+		return StoredUploadResponse{Size: req.Form.Avatar.Size, OnDisk: onDisk}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	upload := func(size int) *httptest.ResponseRecorder {
+		return serve(app, multipartRequest(t, "/avatar", nil, []multipartFile{{"avatar", "a.png", strings.Repeat("x", size)}}))
+	}
+	tests := []struct {
+		name   string
+		size   int
+		status int
+		body   string
+	}{
+		{"under the memory limit", 512, http.StatusOK, `{"size":512,"on_disk":false}`},
+		{"over the memory limit", 16 << 10, http.StatusOK, `{"size":16384,"on_disk":true}`},
+		{"over the hard cap", 128 << 10, http.StatusRequestEntityTooLarge, ""},
+	}
+	for _, tt := range tests {
+		w := upload(tt.size)
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d (body: %s)", tt.name, w.Code, tt.status, w.Body.String())
+			continue
+		}
+		if tt.body != "" && w.Body.String() != tt.body+"\n" {
+			t.Errorf("%s: body = %s, want %s", tt.name, w.Body.String(), tt.body)
+		}
+		// Spilled parts are removed once the response is written
+		if files := leftover(); len(files) != 0 {
+			t.Errorf("%s: temporary files left: %v", tt.name, files)
+		}
+	}
+}
+
+// multipartTempDir points the temporary files of spilled uploads at a fresh directory and
+// returns a function listing the ones left in it
+func multipartTempDir(t *testing.T) func() []string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	return func() []string {
+		files, err := filepath.Glob(filepath.Join(dir, "multipart-*"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return files
+	}
+}
+
+func TestMultipartTempFilesRemoved(t *testing.T) {
+	leftover := multipartTempDir(t)
+	app := framework.New()
+	app.SetMaxMultipartMemory(1 << 10)
+	handler.POST(app, "/avatar", func(ctx context.Context, req AvatarRequest) (UploadResponse, error) {
+		defer req.Form.Avatar.Content.Close()
+		return UploadResponse{Count: 1}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	for range 3 {
+		w := serve(app, multipartRequest(t, "/avatar", nil, []multipartFile{{"avatar", "a.png", strings.Repeat("x", 16<<10)}}))
+		expectStatus(t, w, http.StatusOK)
+	}
+	if files := leftover(); len(files) != 0 {
+		t.Errorf("temporary files left after the responses: %v", files)
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	app := framework.New()
+	app.SetClientTimeout("X-Request-Timeout", 50*time.Millisecond)
+	handler.GET(app, "/sleep", sleep, func(eo handler.EndpointOptions) {})
+
+	tests := []struct {
+		name, target, header string
+		want                 int
+	}{
+		{"no header", "/sleep?ms=100", "", http.StatusOK},
+		{"within the deadline", "/sleep?ms=1", "1s", http.StatusOK},
+		{"past the deadline", "/sleep?ms=1000", "20ms", http.StatusServiceUnavailable},
+		{"clamped to the max", "/sleep?ms=1000", "1h", http.StatusServiceUnavailable},
+		{"invalid", "/sleep?ms=1", "soon", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		if tt.header != "" {
+			r.Header.Set("X-Request-Timeout", tt.header)
+		}
+		start := time.Now()
+		w := serve(app, r)
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d (body: %s)", tt.name, w.Code, tt.want, w.Body.String())
+		}
+		// The handler aborts at the deadline instead of sleeping the full second
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("%s: took %v, want the handler aborted at the deadline", tt.name, elapsed)
+		}
+	}
+}
@@ -2,7 +2,11 @@ module github.com/RottenNinja-Go/framework
 
 go 1.25
 
-require github.com/go-playground/validator/v10 v10.16.0
+require (
+	github.com/go-playground/validator/v10 v10.16.0
+	github.com/golang-jwt/jwt/v5 v5.3.1
+	golang.org/x/sync v0.17.0
+)
 
 require (
 	github.com/gabriel-vasile/mimetype v1.4.3 // indirect
@@ -1,11 +1,25 @@
 package handler
 
-import "github.com/RottenNinja-Go/framework"
+import (
+	"context"
+	"time"
+
+	"github.com/RottenNinja-Go/framework"
+)
 
 type EndpointOptions interface {
 	SetSummary(summary string)
 	SetDescription(description string)
 	SetTags(tags ...string)
+	SetDisableEnvelope(disable bool)
+	SetSkipValidation(skip bool)
+	SetProduces(contentType string)
+	SetConsumes(contentTypes ...string)
+	SetHidden(hidden bool)
+	SetResponseHeaders(code int, headers map[string]framework.HeaderSpec)
+	SetTimeout(timeout time.Duration)
+	SetSuccessStatus(code int)
+	Use(middleware ...framework.Middleware)
 }
 
 // EndpointBuilder provides a fluent API for building endpoints with optional metadata
@@ -28,6 +42,46 @@ func (b *EndpointBuilder) SetTags(tags ...string) {
 	b.endpoint.SetTags(tags...)
 }
 
+// SetDisableEnvelope disables the framework's response envelope for this endpoint
+func (b *EndpointBuilder) SetDisableEnvelope(disable bool) {
+	b.endpoint.SetDisableEnvelope(disable)
+}
+
+// SetSkipValidation disables struct validation for this endpoint
+func (b *EndpointBuilder) SetSkipValidation(skip bool) {
+	b.endpoint.SetSkipValidation(skip)
+}
+
+// SetProduces sets the content type of successful responses
+func (b *EndpointBuilder) SetProduces(contentType string) {
+	b.endpoint.SetProduces(contentType)
+}
+
+// SetConsumes sets the request content types the endpoint accepts
+func (b *EndpointBuilder) SetConsumes(contentTypes ...string) {
+	b.endpoint.SetConsumes(contentTypes...)
+}
+
+// SetHidden hides the endpoint from the generated OpenAPI spec
+func (b *EndpointBuilder) SetHidden(hidden bool) {
+	b.endpoint.SetHidden(hidden)
+}
+
+// SetResponseHeaders documents the headers sent with responses of the given status code
+func (b *EndpointBuilder) SetResponseHeaders(code int, headers map[string]framework.HeaderSpec) {
+	b.endpoint.SetResponseHeaders(code, headers)
+}
+
+// SetTimeout sets a deadline on the handler's context
+func (b *EndpointBuilder) SetTimeout(timeout time.Duration) {
+	b.endpoint.SetTimeout(timeout)
+}
+
+// SetSuccessStatus sets the status code of successful responses, e.g. 201 for create endpoints
+func (b *EndpointBuilder) SetSuccessStatus(code int) {
+	b.endpoint.SetSuccessStatus(code)
+}
+
 // Use adds one or more middleware functions to the endpoint
 // Middleware is applied in the order it's added (first added = outermost wrapper)
 // Example: Use(logging, auth, ratelimit) wraps as logging(auth(ratelimit(handler)))
@@ -37,8 +91,8 @@ func (b *EndpointBuilder) Use(middleware ...framework.Middleware) {
 
 // GET registers a GET endpoint with type-safe handler using a fluent API
 // Works with both Framework and Group through the Router interface
-// Can be used directly: GET(f, path, handler).Summary("...").Tags("...").Register()
-// Or without metadata: GET(f, path, handler).Register()
+// Metadata is set in optFn: GET(f, path, handler, func(eo EndpointOptions) { eo.SetSummary("...") })
+// Or without metadata: GET(f, path, handler, func(eo EndpointOptions) {})
 func GET[Req any, Resp any](r framework.Router, path string, handler framework.Handler[Req, Resp], optFn func(EndpointOptions)) {
 	hRoute := &EndpointBuilder{endpoint: framework.CreateEndpoint("GET", path, handler)}
 	optFn(hRoute)
@@ -47,8 +101,8 @@ func GET[Req any, Resp any](r framework.Router, path string, handler framework.H
 
 // POST registers a POST endpoint with type-safe handler using a fluent API
 // Works with both Framework and Group through the Router interface
-// Can be used directly: POST(f, path, handler).Summary("...").Tags("...").Register()
-// Or without metadata: POST(f, path, handler).Register()
+// Metadata is set in optFn: POST(f, path, handler, func(eo EndpointOptions) { eo.SetSummary("...") })
+// Or without metadata: POST(f, path, handler, func(eo EndpointOptions) {})
 func POST[Req any, Resp any](r framework.Router, path string, handler framework.Handler[Req, Resp], optFn func(EndpointOptions)) {
 	hRoute := &EndpointBuilder{endpoint: framework.CreateEndpoint("POST", path, handler)}
 	optFn(hRoute)
@@ -57,8 +111,8 @@ func POST[Req any, Resp any](r framework.Router, path string, handler framework.
 
 // PUT registers a PUT endpoint with type-safe handler using a fluent API
 // Works with both Framework and Group through the Router interface
-// Can be used directly: PUT(f, path, handler).Summary("...").Tags("...").Register()
-// Or without metadata: PUT(f, path, handler).Register()
+// Metadata is set in optFn: PUT(f, path, handler, func(eo EndpointOptions) { eo.SetSummary("...") })
+// Or without metadata: PUT(f, path, handler, func(eo EndpointOptions) {})
 func PUT[Req any, Resp any](r framework.Router, path string, handler framework.Handler[Req, Resp], optFn func(EndpointOptions)) {
 	hRoute := &EndpointBuilder{endpoint: framework.CreateEndpoint("PUT", path, handler)}
 	optFn(hRoute)
@@ -67,20 +121,65 @@ func PUT[Req any, Resp any](r framework.Router, path string, handler framework.H
 
 // PATCH registers a PATCH endpoint with type-safe handler using a fluent API
 // Works with both Framework and Group through the Router interface
-// Can be used directly: PATCH(f, path, handler).Summary("...").Tags("...").Register()
-// Or without metadata: PATCH(f, path, handler).Register()
+// Metadata is set in optFn: PATCH(f, path, handler, func(eo EndpointOptions) { eo.SetSummary("...") })
+// Or without metadata: PATCH(f, path, handler, func(eo EndpointOptions) {})
 func PATCH[Req any, Resp any](r framework.Router, path string, handler framework.Handler[Req, Resp], optFn func(EndpointOptions)) {
-	hRoute := &EndpointBuilder{endpoint: framework.CreateEndpoint("PATH", path, handler)}
+	hRoute := &EndpointBuilder{endpoint: framework.CreateEndpoint("PATCH", path, handler)}
 	optFn(hRoute)
 	framework.RegisterEndpoint(r, hRoute.endpoint)
 }
 
 // DELETE registers a DELETE endpoint with type-safe handler using a fluent API
 // Works with both Framework and Group through the Router interface
-// Can be used directly: DELETE(f, path, handler).Summary("...").Tags("...").Register()
-// Or without metadata: DELETE(f, path, handler).Register()
+// Metadata is set in optFn: DELETE(f, path, handler, func(eo EndpointOptions) { eo.SetSummary("...") })
+// Or without metadata: DELETE(f, path, handler, func(eo EndpointOptions) {})
 func DELETE[Req any, Resp any](r framework.Router, path string, handler framework.Handler[Req, Resp], optFn func(EndpointOptions)) {
 	hRoute := &EndpointBuilder{endpoint: framework.CreateEndpoint("DELETE", path, handler)}
 	optFn(hRoute)
 	framework.RegisterEndpoint(r, hRoute.endpoint)
 }
+
+// Provider resolves a per-request dependency from the request context
+type Provider[Dep any] func(ctx context.Context) (Dep, error)
+
+// HandlerWith is a type-safe handler that also receives a per-request dependency
+type HandlerWith[Dep any, Req any, Resp any] func(ctx context.Context, dep Dep, req Req) (Resp, error)
+
+// withDependency adapts a HandlerWith into a framework.Handler
+// The provider runs after the request is parsed and validated; its error becomes the handler error
+func withDependency[Dep any, Req any, Resp any](provide Provider[Dep], fn HandlerWith[Dep, Req, Resp]) framework.Handler[Req, Resp] {
+	return func(ctx context.Context, req Req) (Resp, error) {
+		dep, err := provide(ctx)
+		if err != nil {
+			var zero Resp
+			return zero, err
+		}
+		return fn(ctx, dep, req)
+	}
+}
+
+// GETWith registers a GET endpoint whose handler receives a dependency resolved per request
+// Example: GETWith(r, "/users/{id}", openTx, GetUserTx, func(eo EndpointOptions) {})
+func GETWith[Dep any, Req any, Resp any](r framework.Router, path string, provide Provider[Dep], fn HandlerWith[Dep, Req, Resp], optFn func(EndpointOptions)) {
+	GET(r, path, withDependency(provide, fn), optFn)
+}
+
+// POSTWith registers a POST endpoint whose handler receives a dependency resolved per request
+func POSTWith[Dep any, Req any, Resp any](r framework.Router, path string, provide Provider[Dep], fn HandlerWith[Dep, Req, Resp], optFn func(EndpointOptions)) {
+	POST(r, path, withDependency(provide, fn), optFn)
+}
+
+// PUTWith registers a PUT endpoint whose handler receives a dependency resolved per request
+func PUTWith[Dep any, Req any, Resp any](r framework.Router, path string, provide Provider[Dep], fn HandlerWith[Dep, Req, Resp], optFn func(EndpointOptions)) {
+	PUT(r, path, withDependency(provide, fn), optFn)
+}
+
+// PATCHWith registers a PATCH endpoint whose handler receives a dependency resolved per request
+func PATCHWith[Dep any, Req any, Resp any](r framework.Router, path string, provide Provider[Dep], fn HandlerWith[Dep, Req, Resp], optFn func(EndpointOptions)) {
+	PATCH(r, path, withDependency(provide, fn), optFn)
+}
+
+// DELETEWith registers a DELETE endpoint whose handler receives a dependency resolved per request
+func DELETEWith[Dep any, Req any, Resp any](r framework.Router, path string, provide Provider[Dep], fn HandlerWith[Dep, Req, Resp], optFn func(EndpointOptions)) {
+	DELETE(r, path, withDependency(provide, fn), optFn)
+}
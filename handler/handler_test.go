@@ -0,0 +1,201 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RottenNinja-Go/framework"
+	"github.com/RottenNinja-Go/framework/handler"
+	"github.com/RottenNinja-Go/framework/openapi"
+)
+
+// Tx is a stand-in for a database transaction
+type Tx struct {
+	committed bool
+}
+
+func (tx *Tx) Commit() {
+	tx.committed = true
+}
+
+type GetUserRequest struct {
+	Route struct {
+		ID string `json:"id" validate:"required"`
+	}
+}
+
+type User struct {
+	ID string `json:"id"`
+}
+
+// serve sends r to app and returns the recorded response
+func serve(app http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	return w
+}
+
+func TestGETWithProvider(t *testing.T) {
+	tx := &Tx{}
+	app := framework.New()
+	handler.GETWith(app, "/users/{id}", func(ctx context.Context) (*Tx, error) {
+		return tx, nil
+	}, func(ctx context.Context, tx *Tx, req GetUserRequest) (User, error) {
+		tx.Commit()
+		return User{ID: req.Route.ID}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	w := serve(app, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	if w.Code != http.StatusOK || w.Body.String() != `{"id":"42"}`+"\n" {
+		t.Fatalf("got %d %s, want 200 with the user", w.Code, w.Body.String())
+	}
+	if !tx.committed {
+		t.Error("handler didn't receive the provided transaction")
+	}
+}
+
+func TestGETWithProviderError(t *testing.T) {
+	called := false
+	app := framework.New()
+	handler.GETWith(app, "/users/{id}", func(ctx context.Context) (*Tx, error) {
+		return nil, errors.New("database unavailable")
+	}, func(ctx context.Context, tx *Tx, req GetUserRequest) (User, error) {
+		called = true
+		return User{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	w := serve(app, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+	if called {
+		t.Error("handler ran despite the provider error")
+	}
+}
+
+type MethodResponse struct {
+	Method string `json:"method"`
+}
+
+func TestVerbs(t *testing.T) {
+	type register func(r framework.Router, path string, h framework.Handler[framework.NoRequest, MethodResponse], optFn func(handler.EndpointOptions))
+
+	tests := []struct {
+		method    string
+		register  register
+		operation func(openapi.PathItem) *openapi.Operation
+	}{
+		{http.MethodGet, handler.GET[framework.NoRequest, MethodResponse], func(p openapi.PathItem) *openapi.Operation { return p.Get }},
+		{http.MethodPost, handler.POST[framework.NoRequest, MethodResponse], func(p openapi.PathItem) *openapi.Operation { return p.Post }},
+		{http.MethodPut, handler.PUT[framework.NoRequest, MethodResponse], func(p openapi.PathItem) *openapi.Operation { return p.Put }},
+		{http.MethodPatch, handler.PATCH[framework.NoRequest, MethodResponse], func(p openapi.PathItem) *openapi.Operation { return p.Patch }},
+		{http.MethodDelete, handler.DELETE[framework.NoRequest, MethodResponse], func(p openapi.PathItem) *openapi.Operation { return p.Delete }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			app := framework.New()
+			tt.register(app, "/resource", func(ctx context.Context, _ framework.NoRequest) (MethodResponse, error) {
+				return MethodResponse{Method: tt.method}, nil
+			}, func(eo handler.EndpointOptions) {})
+
+			w := serve(app, httptest.NewRequest(tt.method, "/resource", nil))
+			if w.Code != http.StatusOK || w.Body.String() != `{"method":"`+tt.method+`"}`+"\n" {
+				t.Errorf("%s /resource = %d %s, want the handler to run", tt.method, w.Code, w.Body.String())
+			}
+
+			spec := openapi.NewOpenApi(app).GenerateOpenAPI("Test API", "", "1.0.0")
+			path := spec.Paths["/resource"]
+			if tt.operation(path) == nil {
+				t.Errorf("spec has no %s operation on /resource", tt.method)
+			}
+			operations := 0
+			for _, op := range []*openapi.Operation{path.Get, path.Post, path.Put, path.Patch, path.Delete} {
+				if op != nil {
+					operations++
+				}
+			}
+			if operations != 1 {
+				t.Errorf("spec has %d operations on /resource, want only %s", operations, tt.method)
+			}
+		})
+	}
+}
+
+type CreateUserRequest struct {
+	Body struct {
+		ID string `json:"id" validate:"required"`
+	}
+}
+
+type UpdateUserRequest struct {
+	Route struct {
+		ID string `json:"id" validate:"required"`
+	}
+	Body struct {
+		Name string `json:"name" validate:"required"`
+	}
+}
+
+type NamedUser struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// sendJSON sends a request with a JSON body to app
+func sendJSON(app http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	return serve(app, r)
+}
+
+func TestCRUD(t *testing.T) {
+	users := map[string]NamedUser{}
+	notFound := errors.New("user not found")
+
+	app := framework.New()
+	app.MapError(notFound, http.StatusNotFound, "")
+	api := app.Group("/api")
+	handler.POST(api, "/users", func(ctx context.Context, req CreateUserRequest) (NamedUser, error) {
+		users[req.Body.ID] = NamedUser{ID: req.Body.ID}
+		return users[req.Body.ID], nil
+	}, func(eo handler.EndpointOptions) {
+		eo.SetSuccessStatus(http.StatusCreated)
+	})
+	handler.GET(api, "/users/{id}", func(ctx context.Context, req GetUserRequest) (NamedUser, error) {
+		user, ok := users[req.Route.ID]
+		if !ok {
+			return NamedUser{}, notFound
+		}
+		return user, nil
+	}, func(eo handler.EndpointOptions) {})
+	handler.PUT(api, "/users/{id}", func(ctx context.Context, req UpdateUserRequest) (NamedUser, error) {
+		users[req.Route.ID] = NamedUser{ID: req.Route.ID, Name: req.Body.Name}
+		return users[req.Route.ID], nil
+	}, func(eo handler.EndpointOptions) {})
+	handler.DELETE(api, "/users/{id}", func(ctx context.Context, req GetUserRequest) (framework.NoRequest, error) {
+		delete(users, req.Route.ID)
+		return framework.NoRequest{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	steps := []struct {
+		method, target, body string
+		status               int
+		response             string
+	}{
+		{http.MethodPost, "/api/users", `{"id":"7"}`, http.StatusCreated, `{"id":"7","name":""}`},
+		{http.MethodPut, "/api/users/7", `{"name":"Ada"}`, http.StatusOK, `{"id":"7","name":"Ada"}`},
+		{http.MethodGet, "/api/users/7", "", http.StatusOK, `{"id":"7","name":"Ada"}`},
+		{http.MethodDelete, "/api/users/7", "", http.StatusOK, `{}`},
+		{http.MethodGet, "/api/users/7", "", http.StatusNotFound, `{"error":"user not found"}`},
+	}
+	for _, step := range steps {
+		w := sendJSON(app, step.method, step.target, step.body)
+		if w.Code != step.status || w.Body.String() != step.response+"\n" {
+			t.Fatalf("%s %s = %d %s, want %d %s", step.method, step.target, w.Code, w.Body.String(), step.status, step.response)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package framework
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// coerceJSONBody decodes a JSON document and converts string values to numbers or
+// booleans wherever the target type expects them, returning the re-encoded document
+func coerceJSONBody(body io.Reader, target reflect.Type) ([]byte, error) {
+	decoder := json.NewDecoder(body)
+	decoder.UseNumber()
+
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(coerceJSONValue(document, target))
+}
+
+// coerceJSONValue walks a decoded JSON value alongside the Go type it will be decoded into
+func coerceJSONValue(value any, t reflect.Type) any {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		switch t.Kind() {
+		case reflect.Struct:
+			fieldTypes := jsonFieldTypes(t)
+			for key, item := range v {
+				if fieldType, ok := lookupJSONField(fieldTypes, key); ok {
+					v[key] = coerceJSONValue(item, fieldType)
+				}
+			}
+		case reflect.Map:
+			for key, item := range v {
+				v[key] = coerceJSONValue(item, t.Elem())
+			}
+		}
+		return v
+	case []any:
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			for i, item := range v {
+				v[i] = coerceJSONValue(item, t.Elem())
+			}
+		}
+		return v
+	case string:
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			if _, err := strconv.ParseFloat(v, 64); err == nil {
+				return json.Number(v)
+			}
+		case reflect.Bool:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b
+			}
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// jsonFieldTypes maps JSON field names to their Go types, including promoted embedded fields
+func jsonFieldTypes(t reflect.Type) map[string]reflect.Type {
+	fieldTypes := make(map[string]reflect.Type)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if jsonName == "-" {
+			continue
+		}
+
+		// Promote fields of untagged embedded structs, as encoding/json does
+		if field.Anonymous && jsonName == "" {
+			embeddedType := field.Type
+			if embeddedType.Kind() == reflect.Ptr {
+				embeddedType = embeddedType.Elem()
+			}
+			if embeddedType.Kind() == reflect.Struct {
+				for name, fieldType := range jsonFieldTypes(embeddedType) {
+					if _, exists := fieldTypes[name]; !exists {
+						fieldTypes[name] = fieldType
+					}
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		fieldTypes[jsonName] = field.Type
+	}
+
+	return fieldTypes
+}
+
+// lookupJSONField finds a field by exact name, falling back to a case-insensitive match like encoding/json
+func lookupJSONField(fieldTypes map[string]reflect.Type, key string) (reflect.Type, bool) {
+	if fieldType, ok := fieldTypes[key]; ok {
+		return fieldType, true
+	}
+	for name, fieldType := range fieldTypes {
+		if strings.EqualFold(name, key) {
+			return fieldType, true
+		}
+	}
+	return nil, false
+}
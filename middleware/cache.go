@@ -0,0 +1,204 @@
+package middleware
+
+import (
+	"bytes"
+	"container/list"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RottenNinja-Go/framework"
+)
+
+// CacheSize is the maximum number of responses kept by each Cache middleware
+// The least recently used response is evicted once the cache is full
+const CacheSize = 1000
+
+// CacheBodyLimit is the largest response body the Cache middleware stores
+// Larger responses are passed through without being kept in memory
+const CacheBodyLimit = 1 << 20
+
+// Cache serves successful GET and HEAD responses from an in-memory LRU cache for ttl
+// keyFn derives the cache key from the request; nil uses the method and request URI and
+// bypasses requests carrying Authorization or Cookie headers, since their responses may
+// be specific to the caller. Pass a keyFn that includes the caller's identity to cache those
+// Requests or responses with Cache-Control: no-store bypass the cache, and responses with
+// Cache-Control: private, Set-Cookie, streamed (flushed) bodies or bodies over
+// CacheBodyLimit are never stored
+func Cache(ttl time.Duration, keyFn func(*http.Request) string) framework.Middleware {
+	skipCredentials := keyFn == nil
+	if keyFn == nil {
+		keyFn = func(r *http.Request) string {
+			return r.Method + " " + r.URL.RequestURI()
+		}
+	}
+	cache := newResponseCache(CacheSize)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if (r.Method != http.MethodGet && r.Method != http.MethodHead) || hasCacheControl(r.Header, "no-store") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if skipCredentials && (r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != "") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := keyFn(r)
+			if cached := cache.get(key); cached != nil {
+				for name, values := range cached.Header {
+					w.Header()[name] = values
+				}
+				w.WriteHeader(cached.StatusCode)
+				w.Write(cached.Body)
+				return
+			}
+
+			recorder := &cacheRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			if recorder.statusCode < 200 || recorder.statusCode >= 300 || recorder.uncacheable {
+				return
+			}
+			header := w.Header()
+			if hasCacheControl(header, "no-store") || hasCacheControl(header, "private") || header.Get("Set-Cookie") != "" {
+				return
+			}
+
+			cache.set(key, &StoredResponse{
+				StatusCode: recorder.statusCode,
+				Header:     w.Header().Clone(),
+				Body:       bytes.Clone(recorder.body.Bytes()),
+			}, time.Now().Add(ttl))
+		})
+	}
+}
+
+// hasCacheControl reports whether the Cache-Control header contains the given directive
+func hasCacheControl(header http.Header, directive string) bool {
+	for _, value := range header.Values("Cache-Control") {
+		for _, d := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(d), directive) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// cacheRecorder passes writes through while keeping the status code and the body,
+// giving up on the copy once the response is flushed or grows past CacheBodyLimit
+type cacheRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+	uncacheable bool
+}
+
+// WriteHeader records the status code and forwards it
+func (r *cacheRecorder) WriteHeader(statusCode int) {
+	if r.wroteHeader {
+		return
+	}
+	r.statusCode = statusCode
+	r.wroteHeader = true
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records the body while it fits the limit and forwards it
+func (r *cacheRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if !r.uncacheable {
+		if r.body.Len()+len(b) > CacheBodyLimit {
+			r.uncacheable = true
+			r.body = bytes.Buffer{}
+		} else {
+			r.body.Write(b)
+		}
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// FlushError marks the response as streamed and flushes the underlying ResponseWriter
+func (r *cacheRecorder) FlushError() error {
+	r.uncacheable = true
+	r.body = bytes.Buffer{}
+	return http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+// Flush implements http.Flusher for handlers that flush without http.ResponseController
+func (r *cacheRecorder) Flush() {
+	r.FlushError()
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (r *cacheRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// responseCache is a size-bounded LRU cache of responses with per-entry expiry
+type responseCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // most recently used at the front
+	entries map[string]*list.Element
+}
+
+// responseCacheEntry is a cached response and its expiry time
+type responseCacheEntry struct {
+	key       string
+	response  *StoredResponse
+	expiresAt time.Time
+}
+
+func newResponseCache(size int) *responseCache {
+	return &responseCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// get returns the unexpired response for key, or nil
+func (c *responseCache) get(key string) *StoredResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	entry := element.Value.(*responseCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil
+	}
+
+	c.order.MoveToFront(element)
+	return entry.response
+}
+
+// set stores the response for key until expiresAt, evicting the least recently used entry if full
+func (c *responseCache) set(key string, response *StoredResponse, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = &responseCacheEntry{key: key, response: response, expiresAt: expiresAt}
+		c.order.MoveToFront(element)
+		return
+	}
+
+	if c.order.Len() >= c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*responseCacheEntry).key)
+	}
+	c.entries[key] = c.order.PushFront(&responseCacheEntry{key: key, response: response, expiresAt: expiresAt})
+}
@@ -0,0 +1,93 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RottenNinja-Go/framework/middleware"
+)
+
+// countingHandler counts its calls and writes the call number, letting respond
+// customize the response first
+func countingHandler(calls *int, respond func(w http.ResponseWriter)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		if respond != nil {
+			respond(w)
+		}
+		w.Write([]byte{byte('0' + *calls)})
+	})
+}
+
+// getWith sends a GET request to h with the given request headers
+func getWith(h http.Handler, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/items", nil)
+	for name, values := range header {
+		r.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestCacheServesWithinTTL(t *testing.T) {
+	calls := 0
+	h := middleware.Cache(50*time.Millisecond, nil)(countingHandler(&calls, nil))
+
+	first, second := getWith(h, nil), getWith(h, nil)
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls)
+	}
+	if second.Code != http.StatusOK || second.Body.String() != first.Body.String() {
+		t.Errorf("cached response = %d %q, want %d %q", second.Code, second.Body.String(), first.Code, first.Body.String())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if getWith(h, nil); calls != 2 {
+		t.Errorf("handler ran %d times after the TTL, want 2", calls)
+	}
+}
+
+func TestCacheBypass(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  http.Header
+		respond func(w http.ResponseWriter)
+	}{
+		{"request no-store", http.Header{"Cache-Control": {"no-store"}}, nil},
+		{"authorization", http.Header{"Authorization": {"Bearer token"}}, nil},
+		{"cookie", http.Header{"Cookie": {"session=abc"}}, nil},
+		{"response no-store", nil, func(w http.ResponseWriter) {
+			w.Header().Set("Cache-Control", "no-store")
+		}},
+		{"private", nil, func(w http.ResponseWriter) {
+			w.Header().Set("Cache-Control", "private, max-age=60")
+		}},
+		{"set-cookie", nil, func(w http.ResponseWriter) {
+			w.Header().Set("Set-Cookie", "session=abc")
+		}},
+		{"streamed", nil, func(w http.ResponseWriter) {
+			w.Write([]byte("chunk"))
+			http.NewResponseController(w).Flush()
+		}},
+		{"oversized", nil, func(w http.ResponseWriter) {
+			w.Write(make([]byte, middleware.CacheBodyLimit+1))
+		}},
+		{"error", nil, func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusNotFound)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			h := middleware.Cache(time.Minute, nil)(countingHandler(&calls, tt.respond))
+			getWith(h, tt.header)
+			getWith(h, tt.header)
+			if calls != 2 {
+				t.Errorf("handler ran %d times, want the cache bypassed", calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/RottenNinja-Go/framework"
+)
+
+// Concurrency limits the number of requests handled at the same time
+// Requests arriving while all slots are taken receive 503 Service Unavailable with a
+// Retry-After header instead of queueing. Slots are released even if the handler panics
+// A max of 0 or less means no limit
+func Concurrency(max int) framework.Middleware {
+	if max <= 0 {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+	slots := make(chan struct{}, max)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case slots <- struct{}{}:
+			default:
+				w.Header().Set("Retry-After", "1")
+				writeError(w, http.StatusServiceUnavailable, "too many concurrent requests")
+				return
+			}
+			defer func() { <-slots }()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
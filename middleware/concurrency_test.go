@@ -0,0 +1,96 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/RottenNinja-Go/framework/middleware"
+)
+
+// blockingHandler holds requests until release is closed
+type blockingHandler struct {
+	started sync.WaitGroup
+	release chan struct{}
+}
+
+func (b *blockingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.started.Done()
+	<-b.release
+}
+
+// get sends a GET request to h
+func get(h http.Handler) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	return w
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	blocking := &blockingHandler{release: make(chan struct{})}
+	h := middleware.Concurrency(2)(blocking)
+
+	// Saturate both slots
+	var done sync.WaitGroup
+	blocking.started.Add(2)
+	for range 2 {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			get(h)
+		}()
+	}
+	blocking.started.Wait()
+
+	w := get(h)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+		t.Errorf("got %d with Retry-After %q, want 503 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	close(blocking.release)
+	done.Wait()
+
+	// Freed slots are available again
+	blocking.started.Add(1)
+	if w := get(h); w.Code != http.StatusOK {
+		t.Errorf("status after release = %d, want 200", w.Code)
+	}
+}
+
+func TestConcurrencyReleasesOnPanic(t *testing.T) {
+	h := middleware.Concurrency(1)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	for range 2 {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("panic was swallowed")
+				}
+			}()
+			get(h)
+		}()
+	}
+}
+
+func TestConcurrencyZeroIsUnlimited(t *testing.T) {
+	blocking := &blockingHandler{release: make(chan struct{})}
+	h := middleware.Concurrency(0)(blocking)
+
+	var done sync.WaitGroup
+	blocking.started.Add(10)
+	for range 10 {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			if w := get(h); w.Code != http.StatusOK {
+				t.Errorf("status = %d, want 200", w.Code)
+			}
+		}()
+	}
+	// All requests run at the same time
+	blocking.started.Wait()
+	close(blocking.release)
+	done.Wait()
+}
@@ -0,0 +1,14 @@
+package middleware
+
+import (
+	"github.com/RottenNinja-Go/framework"
+)
+
+// CORSOptions configures the CORS middleware
+type CORSOptions = framework.CORSOptions
+
+// CORS sets the Access-Control-Allow-* headers for requests from allowed origins and
+// answers preflight requests with 204 No Content; see framework.CORS
+func CORS(opts CORSOptions) framework.Middleware {
+	return framework.CORS(opts)
+}
@@ -0,0 +1,116 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RottenNinja-Go/framework"
+	"github.com/RottenNinja-Go/framework/handler"
+	"github.com/RottenNinja-Go/framework/middleware"
+)
+
+type Item struct {
+	Name string `json:"name"`
+}
+
+func listItems(ctx context.Context, _ framework.NoRequest) (Item, error) {
+	return Item{Name: "widget"}, nil
+}
+
+// newCORSApp returns an app with CORS scoped to the /api/v1 group
+func newCORSApp() *framework.Framework {
+	app := framework.New()
+	api := app.Group("/api/v1")
+	api.Use(middleware.CORS(middleware.CORSOptions{
+		AllowedOrigins:   []string{"https://app.example"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type"},
+		ExposedHeaders:   []string{"X-Request-Id"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}))
+	handler.GET(api, "/items", listItems, func(eo handler.EndpointOptions) {})
+	handler.POST(api, "/items", listItems, func(eo handler.EndpointOptions) {})
+	handler.GET(app, "/health", listItems, func(eo handler.EndpointOptions) {})
+	return app
+}
+
+// crossOrigin sends a request from origin to app
+func crossOrigin(app http.Handler, method, target, origin string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	for name, values := range header {
+		r.Header[name] = values
+	}
+	r.Header.Set("Origin", origin)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	return w
+}
+
+func TestCORSSimpleRequest(t *testing.T) {
+	w := crossOrigin(newCORSApp(), http.MethodGet, "/api/v1/items", "https://app.example", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	want := map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Expose-Headers":    "X-Request-Id",
+		"Vary":                             "Origin",
+		"Access-Control-Allow-Methods":     "",
+	}
+	for name, value := range want {
+		if got := w.Header().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	w := crossOrigin(newCORSApp(), http.MethodOptions, "/api/v1/items", "https://app.example", http.Header{
+		"Access-Control-Request-Method":  {http.MethodPost},
+		"Access-Control-Request-Headers": {"Content-Type"},
+	})
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", w.Code)
+	}
+	want := map[string]string{
+		"Access-Control-Allow-Origin":  "https://app.example",
+		"Access-Control-Allow-Methods": "GET, HEAD, POST, PUT, PATCH, DELETE",
+		"Access-Control-Allow-Headers": "Authorization, Content-Type",
+		"Access-Control-Max-Age":       "600",
+	}
+	for name, value := range want {
+		if got := w.Header().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("body = %q, want none", w.Body.String())
+	}
+}
+
+func TestCORSDisallowedOrigin(t *testing.T) {
+	w := crossOrigin(newCORSApp(), http.MethodGet, "/api/v1/items", "https://evil.example", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want none", got)
+	}
+	if got := w.Header().Get("Vary"); got != "Origin" {
+		t.Errorf("Vary = %q, want Origin", got)
+	}
+}
+
+func TestCORSGroupScope(t *testing.T) {
+	w := crossOrigin(newCORSApp(), http.MethodGet, "/health", "https://app.example", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want none outside the group", got)
+	}
+}
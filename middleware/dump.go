@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+
+	"github.com/RottenNinja-Go/framework"
+)
+
+// DumpBodyLimit is the maximum number of body bytes DumpRequestResponse writes per body
+// Longer bodies are truncated in the dump; the handler and client still see them in full
+const DumpBodyLimit = 64 << 10
+
+// DumpRequestResponse writes each raw request (method, path, headers, body) and its
+// response (status, headers, body) to out, for debugging integrations
+// The request body is re-buffered so the handler still reads it. A nil out disables
+// dumping entirely, so it can stay wired up and be switched off in production:
+//
+//	var dumpTo io.Writer
+//	if debug {
+//		dumpTo = os.Stderr
+//	}
+//	api.Use(middleware.DumpRequestResponse(dumpTo))
+func DumpRequestResponse(out io.Writer) framework.Middleware {
+	var mu sync.Mutex
+
+	return func(next http.Handler) http.Handler {
+		if out == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestDump, _ := httputil.DumpRequest(r, false)
+
+			// Read at most the limit and put it back in front of the unread rest
+			var requestBody []byte
+			if r.Body != nil && r.Body != http.NoBody {
+				requestBody, _ = io.ReadAll(io.LimitReader(r.Body, DumpBodyLimit+1))
+				r.Body = readCloser{io.MultiReader(bytes.NewReader(requestBody), r.Body), r.Body}
+			}
+
+			recorder := &dumpRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			var dump bytes.Buffer
+			dump.Write(requestDump)
+			writeDumpBody(&dump, requestBody)
+			fmt.Fprintf(&dump, "%s %d %s\r\n", r.Proto, recorder.statusCode, http.StatusText(recorder.statusCode))
+			w.Header().Write(&dump)
+			dump.WriteString("\r\n")
+			writeDumpBody(&dump, recorder.body.Bytes())
+
+			mu.Lock()
+			defer mu.Unlock()
+			out.Write(dump.Bytes())
+		})
+	}
+}
+
+// writeDumpBody writes body to dump, truncated to DumpBodyLimit
+func writeDumpBody(dump *bytes.Buffer, body []byte) {
+	if len(body) > DumpBodyLimit {
+		dump.Write(body[:DumpBodyLimit])
+		dump.WriteString("\n... (truncated)")
+	} else {
+		dump.Write(body)
+	}
+	dump.WriteString("\n\n")
+}
+
+// readCloser reads from a re-assembled body and closes the original
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// dumpRecorder passes writes through while keeping the status code and
+// the first DumpBodyLimit+1 bytes of the body
+type dumpRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+// WriteHeader records the status code and forwards it
+func (r *dumpRecorder) WriteHeader(statusCode int) {
+	if r.wroteHeader {
+		return
+	}
+	r.statusCode = statusCode
+	r.wroteHeader = true
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records up to the dump limit and forwards the full body
+func (r *dumpRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	if remaining := DumpBodyLimit + 1 - r.body.Len(); remaining > 0 {
+		r.body.Write(b[:min(len(b), remaining)])
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (r *dumpRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
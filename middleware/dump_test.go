@@ -0,0 +1,75 @@
+package middleware_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RottenNinja-Go/framework/middleware"
+)
+
+// echo responds with the request body
+var echo = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("X-Echo", "yes")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(body)
+})
+
+func TestDumpRequestResponse(t *testing.T) {
+	var dump bytes.Buffer
+	h := middleware.DumpRequestResponse(&dump)(echo)
+
+	r := httptest.NewRequest(http.MethodPost, "/echo?x=1", strings.NewReader(`{"ping":true}`))
+	r.Header.Set("X-Request", "abc")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	// The handler still reads the whole body
+	if w.Body.String() != `{"ping":true}` {
+		t.Errorf("response body = %q, want the echoed request body", w.Body.String())
+	}
+
+	out := dump.String()
+	for _, want := range []string{
+		"POST /echo?x=1", "X-Request: abc", `{"ping":true}`,
+		"201 Created", "X-Echo: yes",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dump is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, `{"ping":true}`) != 2 {
+		t.Errorf("dump should contain both the request and the response body:\n%s", out)
+	}
+}
+
+func TestDumpTruncatesLargeBodies(t *testing.T) {
+	var dump bytes.Buffer
+	h := middleware.DumpRequestResponse(&dump)(echo)
+
+	body := strings.Repeat("x", middleware.DumpBodyLimit*4)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(body)))
+
+	if w.Body.Len() != len(body) {
+		t.Errorf("response body has %d bytes, want %d", w.Body.Len(), len(body))
+	}
+	// Each of the two bodies is capped at the limit
+	if dump.Len() > 2*middleware.DumpBodyLimit+1024 {
+		t.Errorf("dump has %d bytes, want the bodies truncated", dump.Len())
+	}
+}
+
+func TestDumpDisabled(t *testing.T) {
+	calls := 0
+	next := countingHandler(&calls, nil)
+	// A nil writer returns the next handler untouched
+	h := middleware.DumpRequestResponse(nil)(next)
+	if getWith(h, nil); calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+}
@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/RottenNinja-Go/framework"
+)
+
+// IdempotencyKeyHeader is the request header carrying the client's idempotency key
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// StoredResponse is a captured response that can be replayed later
+type StoredResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// IdempotencyStore persists responses by idempotency key
+// Implement this interface to back idempotency with Redis or another shared store
+type IdempotencyStore interface {
+	// Get returns the stored response for key, or nil if none exists
+	Get(ctx context.Context, key string) (*StoredResponse, error)
+	// Set stores the response for key
+	Set(ctx context.Context, key string, resp *StoredResponse) error
+}
+
+// IdempotencyTTL is how long a MemoryIdempotencyStore keeps a response by default
+const IdempotencyTTL = 24 * time.Hour
+
+// IdempotencyStoreSize is the maximum number of responses a MemoryIdempotencyStore keeps
+// by default. The least recently used response is evicted once the store is full
+const IdempotencyStoreSize = 10000
+
+// MemoryIdempotencyStore is an in-memory IdempotencyStore
+// Suitable for single-instance deployments and tests. Responses expire after a TTL and the
+// store is bounded in size, so clients sending unique keys can't grow it without limit
+type MemoryIdempotencyStore struct {
+	ttl       time.Duration
+	responses *responseCache
+}
+
+// NewMemoryIdempotencyStore creates an empty in-memory store keeping up to
+// IdempotencyStoreSize responses for IdempotencyTTL
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		ttl:       IdempotencyTTL,
+		responses: newResponseCache(IdempotencyStoreSize),
+	}
+}
+
+// SetTTL sets how long responses are kept; call it before the store is used
+func (s *MemoryIdempotencyStore) SetTTL(ttl time.Duration) *MemoryIdempotencyStore {
+	s.ttl = ttl
+	return s
+}
+
+// SetMaxEntries sets the maximum number of responses kept; call it before the store is used
+func (s *MemoryIdempotencyStore) SetMaxEntries(n int) *MemoryIdempotencyStore {
+	s.responses.size = n
+	return s
+}
+
+// Get implements IdempotencyStore
+func (s *MemoryIdempotencyStore) Get(_ context.Context, key string) (*StoredResponse, error) {
+	return s.responses.get(key), nil
+}
+
+// Set implements IdempotencyStore
+func (s *MemoryIdempotencyStore) Set(_ context.Context, key string, resp *StoredResponse) error {
+	s.responses.set(key, resp, time.Now().Add(s.ttl))
+	return nil
+}
+
+// Idempotency replays the stored response for requests carrying a previously seen
+// Idempotency-Key header instead of running the handler again
+// Requests without the header pass through untouched. Server errors (5xx) are not
+// stored so the client can retry them. Failures to store a response are logged
+func Idempotency(store IdempotencyStore) framework.Middleware {
+	locks := newKeyedMutex()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idempotencyKey := r.Header.Get(IdempotencyKeyHeader)
+			if idempotencyKey == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Scope keys to the endpoint so the same key can't replay another route's response
+			key := r.Method + " " + r.URL.Path + " " + idempotencyKey
+
+			// Serialize concurrent requests with the same key so the handler runs once
+			unlock := locks.lock(key)
+			defer unlock()
+
+			stored, err := store.Get(r.Context(), key)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, "idempotency store unavailable")
+				return
+			}
+			if stored != nil {
+				for name, values := range stored.Header {
+					w.Header()[name] = values
+				}
+				w.WriteHeader(stored.StatusCode)
+				w.Write(stored.Body)
+				return
+			}
+
+			recorder := newResponseRecorder(w)
+			next.ServeHTTP(recorder, r)
+
+			if recorder.statusCode >= http.StatusInternalServerError {
+				return
+			}
+
+			// The response is already sent, so a failed store is only logged; the
+			// store must work for retries to be recognized
+			err = store.Set(context.WithoutCancel(r.Context()), key, &StoredResponse{
+				StatusCode: recorder.statusCode,
+				Header:     w.Header().Clone(),
+				Body:       recorder.body.Bytes(),
+			})
+			if err != nil {
+				log.Printf("idempotency: storing response for %s: %v", key, err)
+			}
+		})
+	}
+}
+
+// keyedMutex provides a mutex per key, released once no request holds it
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedMutexEntry
+}
+
+// keyedMutexEntry is a reference-counted mutex
+type keyedMutexEntry struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{
+		locks: make(map[string]*keyedMutexEntry),
+	}
+}
+
+// lock acquires the mutex for key and returns a function that releases it
+func (k *keyedMutex) lock(key string) func() {
+	k.mu.Lock()
+	entry, ok := k.locks[key]
+	if !ok {
+		entry = &keyedMutexEntry{}
+		k.locks[key] = entry
+	}
+	entry.refs++
+	k.mu.Unlock()
+
+	entry.mu.Lock()
+
+	return func() {
+		entry.mu.Unlock()
+
+		k.mu.Lock()
+		entry.refs--
+		if entry.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
@@ -0,0 +1,66 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RottenNinja-Go/framework/middleware"
+)
+
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	calls := 0
+	h := middleware.Idempotency(middleware.NewMemoryIdempotencyStore())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-Order", "42")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":42}`))
+	}))
+
+	post := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{}`))
+		r.Header.Set(middleware.IdempotencyKeyHeader, "abc")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	first, second := post(), post()
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls)
+	}
+	if second.Code != http.StatusCreated || second.Header().Get("X-Order") != "42" || second.Body.String() != `{"id":42}` {
+		t.Errorf("replayed %d %v %q, want %d %v %q",
+			second.Code, second.Header(), second.Body.String(),
+			first.Code, first.Header(), first.Body.String())
+	}
+}
+
+func TestMemoryIdempotencyStoreEvicts(t *testing.T) {
+	ctx := context.Background()
+	store := middleware.NewMemoryIdempotencyStore().SetMaxEntries(2)
+	for _, key := range []string{"a", "b", "c"} {
+		store.Set(ctx, key, &middleware.StoredResponse{StatusCode: http.StatusCreated})
+	}
+	for key, want := range map[string]bool{"a": false, "b": true, "c": true} {
+		if resp, _ := store.Get(ctx, key); (resp != nil) != want {
+			t.Errorf("%s stored = %v, want %v", key, resp != nil, want)
+		}
+	}
+}
+
+func TestMemoryIdempotencyStoreExpires(t *testing.T) {
+	ctx := context.Background()
+	store := middleware.NewMemoryIdempotencyStore().SetTTL(10 * time.Millisecond)
+	store.Set(ctx, "a", &middleware.StoredResponse{StatusCode: http.StatusCreated})
+	if resp, _ := store.Get(ctx, "a"); resp == nil {
+		t.Fatal("response missing before the TTL")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if resp, _ := store.Get(ctx, "a"); resp != nil {
+		t.Error("response still stored after the TTL")
+	}
+}
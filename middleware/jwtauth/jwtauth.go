@@ -0,0 +1,90 @@
+// Package jwtauth provides bearer token authentication middleware backed by golang-jwt
+// It lives in its own package so the core framework doesn't depend on a JWT library
+package jwtauth
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/RottenNinja-Go/framework"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// config holds the JWT middleware configuration
+type config struct {
+	newClaims     func() jwt.Claims
+	parserOptions []jwt.ParserOption
+}
+
+// Option configures the JWT middleware
+type Option func(*config)
+
+// WithClaims sets the factory used to create the claims value for each request
+// Defaults to jwt.MapClaims
+func WithClaims(newClaims func() jwt.Claims) Option {
+	return func(c *config) {
+		c.newClaims = newClaims
+	}
+}
+
+// WithParserOptions passes additional options to the JWT parser (e.g. jwt.WithValidMethods)
+func WithParserOptions(opts ...jwt.ParserOption) Option {
+	return func(c *config) {
+		c.parserOptions = append(c.parserOptions, opts...)
+	}
+}
+
+// JWT returns a middleware that validates the bearer token in the Authorization header
+// On success the parsed claims are stored in the request context and can be read
+// with framework.ClaimsFromContext. On failure it responds with 401 Unauthorized
+func JWT(keyFunc jwt.Keyfunc, opts ...Option) framework.Middleware {
+	cfg := &config{
+		newClaims: func() jwt.Claims { return jwt.MapClaims{} },
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	parser := jwt.NewParser(cfg.parserOptions...)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tokenString, ok := bearerToken(r)
+			if !ok {
+				writeUnauthorized(w, "missing bearer token")
+				return
+			}
+
+			token, err := parser.ParseWithClaims(tokenString, cfg.newClaims(), keyFunc)
+			if err != nil || !token.Valid {
+				writeUnauthorized(w, "invalid token")
+				return
+			}
+
+			ctx := framework.WithClaims(r.Context(), token.Claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// writeUnauthorized writes a 401 JSON error response
+func writeUnauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(framework.ErrorResponse{
+		Error: message,
+	})
+}
@@ -0,0 +1,80 @@
+package jwtauth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RottenNinja-Go/framework"
+	"github.com/RottenNinja-Go/framework/middleware/jwtauth"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var secret = []byte("test-secret")
+
+func keyFunc(*jwt.Token) (any, error) {
+	return secret, nil
+}
+
+// signedToken returns an HS256 token for subject expiring at expiresAt
+func signedToken(t *testing.T, subject string, expiresAt time.Time) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": subject,
+		"exp": expiresAt.Unix(),
+	}).SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// serveWithToken runs the JWT middleware for a request with the given Authorization
+// header and returns the response and the subject the handler saw
+func serveWithToken(authorization string) (*httptest.ResponseRecorder, string) {
+	var subject string
+	h := jwtauth.JWT(keyFunc)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := framework.ClaimsFromContext(r.Context())
+		if !ok {
+			http.Error(w, "no claims", http.StatusInternalServerError)
+			return
+		}
+		subject, _ = claims.(jwt.MapClaims).GetSubject()
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if authorization != "" {
+		r.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w, subject
+}
+
+func TestJWTValidToken(t *testing.T) {
+	w, subject := serveWithToken("Bearer " + signedToken(t, "user-1", time.Now().Add(time.Hour)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (body: %s)", w.Code, w.Body.String())
+	}
+	if subject != "user-1" {
+		t.Errorf("subject = %q, want user-1", subject)
+	}
+}
+
+func TestJWTExpiredToken(t *testing.T) {
+	w, _ := serveWithToken("Bearer " + signedToken(t, "user-1", time.Now().Add(-time.Hour)))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+}
+
+func TestJWTMissingHeader(t *testing.T) {
+	w, _ := serveWithToken("")
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", w.Code)
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/RottenNinja-Go/framework"
+)
+
+// Recover recovers panics in later handlers, logs them with their stack trace and
+// responds with 500 Internal Server Error instead of dropping the connection
+// If the handler already started its response, the panic is only logged, since the status
+// can't change anymore. http.ErrAbortHandler is re-panicked so net/http still aborts the
+// response silently
+func Recover() framework.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tracker := &commitTracker{ResponseWriter: w}
+			defer func() {
+				p := recover()
+				if p == nil {
+					return
+				}
+				if p == http.ErrAbortHandler {
+					panic(p)
+				}
+
+				log.Printf("panic serving %s %s: %v\n%s", r.Method, r.URL.Path, p, debug.Stack())
+				if !tracker.committed {
+					writeError(w, http.StatusInternalServerError, "internal server error")
+				}
+			}()
+			next.ServeHTTP(tracker, r)
+		})
+	}
+}
+
+// commitTracker passes writes through, noting once the response has been started
+type commitTracker struct {
+	http.ResponseWriter
+	committed bool
+}
+
+// WriteHeader marks the response as started and forwards the status code
+// Informational (1xx) responses don't start the final response
+func (t *commitTracker) WriteHeader(statusCode int) {
+	if statusCode >= 200 {
+		t.committed = true
+	}
+	t.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write marks the response as started and forwards the body
+func (t *commitTracker) Write(b []byte) (int, error) {
+	t.committed = true
+	return t.ResponseWriter.Write(b)
+}
+
+// FlushError marks the response as started and flushes the underlying ResponseWriter
+func (t *commitTracker) FlushError() error {
+	t.committed = true
+	return http.NewResponseController(t.ResponseWriter).Flush()
+}
+
+// Flush implements http.Flusher for handlers that flush without http.ResponseController
+func (t *commitTracker) Flush() {
+	t.FlushError()
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (t *commitTracker) Unwrap() http.ResponseWriter {
+	return t.ResponseWriter
+}
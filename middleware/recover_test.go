@@ -0,0 +1,68 @@
+package middleware_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/RottenNinja-Go/framework/middleware"
+)
+
+// panicking returns a handler that panics with p
+func panicking(p any) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(p)
+	})
+}
+
+func TestRecover(t *testing.T) {
+	var logs bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logs)
+
+	for _, p := range []any{"boom", errors.New("database is down")} {
+		logs.Reset()
+		w := get(middleware.Recover()(panicking(p)))
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("%v: status = %d, want 500", p, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("%v: Content-Type = %q, want application/json", p, ct)
+		}
+		if want := `{"error":"internal server error"}` + "\n"; w.Body.String() != want {
+			t.Errorf("%v: body = %s, want %s", p, w.Body.String(), want)
+		}
+		if !strings.Contains(logs.String(), "panic serving GET /") || !strings.Contains(logs.String(), "goroutine") {
+			t.Errorf("%v: log = %q, want the panic and its stack", p, logs.String())
+		}
+	}
+}
+
+func TestRecoverAfterResponseStarted(t *testing.T) {
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(io.Discard)
+
+	h := middleware.Recover()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("partial"))
+		panic("boom")
+	}))
+	w := get(h)
+	if w.Code != http.StatusOK || w.Body.String() != "partial" {
+		t.Errorf("response = %d %q, want the started response left alone", w.Code, w.Body.String())
+	}
+}
+
+func TestRecoverRepanicsAbortHandler(t *testing.T) {
+	defer func() {
+		if p := recover(); p != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", p)
+		}
+	}()
+	get(middleware.Recover()(panicking(http.ErrAbortHandler)))
+	t.Error("ErrAbortHandler was swallowed")
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/RottenNinja-Go/framework"
+	"golang.org/x/sync/singleflight"
+)
+
+// SingleFlight coalesces concurrent identical GET and HEAD requests into one handler execution
+// Requests arriving while a request with the same key is in flight wait for it and receive
+// a copy of its response. keyFn derives the key from the request; nil uses the method and
+// request URI. Include anything the response depends on (such as the caller's identity)
+// in the key, since waiting requests get the first request's headers and body as they are
+func SingleFlight(keyFn func(*http.Request) string) framework.Middleware {
+	if keyFn == nil {
+		keyFn = func(r *http.Request) string {
+			return r.Method + " " + r.URL.RequestURI()
+		}
+	}
+	var group singleflight.Group
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// The request that runs the handler writes its response directly
+			executed := false
+			result, _, _ := group.Do(keyFn(r), func() (any, error) {
+				executed = true
+				recorder := newResponseRecorder(w)
+				next.ServeHTTP(recorder, r)
+				return &StoredResponse{
+					StatusCode: recorder.statusCode,
+					Header:     w.Header().Clone(),
+					Body:       bytes.Clone(recorder.body.Bytes()),
+				}, nil
+			})
+			if executed {
+				return
+			}
+
+			shared := result.(*StoredResponse)
+			for name, values := range shared.Header {
+				w.Header()[name] = values
+			}
+			w.WriteHeader(shared.StatusCode)
+			w.Write(shared.Body)
+		})
+	}
+}
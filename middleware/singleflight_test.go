@@ -0,0 +1,61 @@
+package middleware_test
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RottenNinja-Go/framework/middleware"
+)
+
+func TestSingleFlight(t *testing.T) {
+	var calls atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	h := middleware.SingleFlight(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		w.Header().Set("X-Result", "computed")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("expensive"))
+	}))
+
+	const n = 10
+	var done sync.WaitGroup
+	results := make(chan [3]string, n)
+	for range n {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			w := get(h)
+			results <- [3]string{http.StatusText(w.Code), w.Header().Get("X-Result"), w.Body.String()}
+		}()
+	}
+
+	// Give the other requests time to join the one in flight
+	<-started
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	done.Wait()
+	close(results)
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("handler ran %d times, want 1", got)
+	}
+	want := [3]string{http.StatusText(http.StatusAccepted), "computed", "expensive"}
+	for result := range results {
+		if result != want {
+			t.Errorf("response = %v, want %v", result, want)
+		}
+	}
+
+	// Later requests run the handler again
+	get(h)
+	if got := calls.Load(); got != 2 {
+		t.Errorf("handler ran %d times after a later request, want 2", got)
+	}
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/RottenNinja-Go/framework"
+)
+
+// Timeout cancels the request context after the given duration and responds with
+// 503 Service Unavailable if the handler hasn't finished by then
+// The 503 carries a Retry-After header derived from the timeout, rounded up to whole seconds
+// The handler's response is buffered so it can be discarded if the timeout fires first
+func Timeout(timeout time.Duration) framework.Middleware {
+	retryAfter := strconv.Itoa(max(1, int(math.Ceil(timeout.Seconds()))))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			tw := &timeoutWriter{header: make(http.Header)}
+			done := make(chan struct{})
+			panicked := make(chan any, 1)
+
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicked <- p
+					}
+				}()
+				next.ServeHTTP(tw, r.WithContext(ctx))
+				close(done)
+			}()
+
+			select {
+			case p := <-panicked:
+				panic(p)
+			case <-done:
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				for name, values := range tw.header {
+					w.Header()[name] = values
+				}
+				w.WriteHeader(tw.statusCode())
+				w.Write(tw.body.Bytes())
+			case <-ctx.Done():
+				tw.mu.Lock()
+				defer tw.mu.Unlock()
+				tw.timedOut = true
+				if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					// The client went away, there's nobody to respond to
+					return
+				}
+				w.Header().Set("Retry-After", retryAfter)
+				writeError(w, http.StatusServiceUnavailable, "request timed out")
+			}
+		})
+	}
+}
+
+// timeoutWriter buffers a handler's response until it completes or times out
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	code     int
+	body     bytes.Buffer
+	timedOut bool
+}
+
+// Header returns the buffered response headers
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+// WriteHeader records the status code
+func (tw *timeoutWriter) WriteHeader(statusCode int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.code != 0 {
+		return
+	}
+	tw.code = statusCode
+}
+
+// Write buffers the body, failing once the timeout has fired
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.code == 0 {
+		tw.code = http.StatusOK
+	}
+	return tw.body.Write(b)
+}
+
+// statusCode returns the recorded status code, defaulting to 200
+func (tw *timeoutWriter) statusCode() int {
+	if tw.code == 0 {
+		return http.StatusOK
+	}
+	return tw.code
+}
@@ -0,0 +1,36 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/RottenNinja-Go/framework/middleware"
+)
+
+// waitForCancel blocks until the request context is done
+var waitForCancel = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	<-r.Context().Done()
+})
+
+func TestTimeoutRetryAfter(t *testing.T) {
+	tests := []struct {
+		timeout time.Duration
+		want    string
+	}{
+		{10 * time.Millisecond, "1"},
+		{1500 * time.Millisecond, "2"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		middleware.Timeout(tt.timeout)(waitForCancel).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("%v: status = %d, want 503", tt.timeout, w.Code)
+		}
+		if got := w.Header().Get("Retry-After"); got != tt.want {
+			t.Errorf("%v: Retry-After = %q, want %q", tt.timeout, got, tt.want)
+		}
+	}
+}
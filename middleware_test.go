@@ -0,0 +1,203 @@
+package framework_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RottenNinja-Go/framework"
+	"github.com/RottenNinja-Go/framework/handler"
+)
+
+// requireToken rejects requests without an Authorization header
+func requireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// okHandler responds with an empty 200 to any request
+func okHandler(ctx context.Context, _ framework.NoRequest) (UploadResponse, error) {
+	return UploadResponse{}, nil
+}
+
+func TestWhen(t *testing.T) {
+	app := framework.New()
+	api := app.Group("/api")
+	api.Use(framework.When(func(r *http.Request) bool {
+		return !strings.HasPrefix(r.URL.Path, "/api/public/")
+	}, requireToken))
+	handler.GET(api, "/public/status", okHandler, func(eo handler.EndpointOptions) {})
+	handler.GET(api, "/account", okHandler, func(eo handler.EndpointOptions) {})
+
+	expectStatus(t, serve(app, httptest.NewRequest(http.MethodGet, "/api/public/status", nil)), http.StatusOK)
+	expectStatus(t, serve(app, httptest.NewRequest(http.MethodGet, "/api/account", nil)), http.StatusUnauthorized)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/account", nil)
+	r.Header.Set("Authorization", "Bearer token")
+	expectStatus(t, serve(app, r), http.StatusOK)
+}
+
+// allowOrigin is a minimal CORS middleware answering every origin
+func allowOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestAutoOptionsPreflight(t *testing.T) {
+	app := framework.New()
+	app.SetAutoOptions(true)
+	api := app.Group("/api").Use(allowOrigin)
+	handler.GET(api, "/users", okHandler, func(eo handler.EndpointOptions) {})
+
+	r := httptest.NewRequest(http.MethodOptions, "/api/users", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	r.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	w := serve(app, r)
+
+	expectStatus(t, w, http.StatusNoContent)
+	if allow := w.Header().Get("Allow"); !strings.Contains(allow, http.MethodGet) || !strings.Contains(allow, http.MethodOptions) {
+		t.Errorf("Allow = %q, want GET and OPTIONS", allow)
+	}
+	if w.Header().Get("Access-Control-Allow-Origin") != "*" || w.Header().Get("Access-Control-Allow-Methods") == "" {
+		t.Errorf("headers = %v, want the CORS headers", w.Header())
+	}
+}
+
+func TestCORS(t *testing.T) {
+	app := framework.New()
+	api := app.Group("/api/v1").Use(framework.CORS(framework.CORSOptions{
+		AllowedOrigins: []string{"https://app.example.com"},
+		MaxAge:         time.Minute,
+	}))
+	handler.GET(api, "/users", okHandler, func(eo handler.EndpointOptions) {})
+	handler.GET(app, "/health", okHandler, func(eo handler.EndpointOptions) {})
+
+	request := func(method, target, origin string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, nil)
+		r.Header.Set("Origin", origin)
+		if method == http.MethodOptions {
+			r.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		}
+		return serve(app, r)
+	}
+
+	tests := []struct {
+		name, method, target, origin string
+		status                       int
+		allowOrigin                  string
+	}{
+		{"simple request", http.MethodGet, "/api/v1/users", "https://app.example.com", http.StatusOK, "https://app.example.com"},
+		{"preflight", http.MethodOptions, "/api/v1/users", "https://app.example.com", http.StatusNoContent, "https://app.example.com"},
+		{"disallowed origin", http.MethodGet, "/api/v1/users", "https://evil.example.com", http.StatusOK, ""},
+		{"outside the group", http.MethodGet, "/health", "https://app.example.com", http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		w := request(tt.method, tt.target, tt.origin)
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.status)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+			t.Errorf("%s: Access-Control-Allow-Origin = %q, want %q", tt.name, got, tt.allowOrigin)
+		}
+	}
+	if got := request(http.MethodOptions, "/api/v1/users", "https://app.example.com").Header().Get("Access-Control-Max-Age"); got != "60" {
+		t.Errorf("Access-Control-Max-Age = %q, want 60", got)
+	}
+}
+
+// trace returns a middleware appending name to the X-Trace response header
+func trace(name string) framework.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Trace", name)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestFrameworkMiddleware(t *testing.T) {
+	app := framework.New()
+	app.Use(trace("framework"))
+	handler.GET(app, "/direct", okHandler, func(eo handler.EndpointOptions) {})
+	api := app.Group("/api").Use(trace("group"))
+	handler.GET(api, "/grouped", okHandler, func(eo handler.EndpointOptions) {
+		eo.Use(trace("endpoint"))
+	})
+
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"/direct", []string{"framework"}},
+		{"/api/grouped", []string{"framework", "group", "endpoint"}},
+	}
+	for _, tt := range tests {
+		w := serve(app, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		expectStatus(t, w, http.StatusOK)
+		if got := w.Header().Values("X-Trace"); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: middleware order = %q, want %q", tt.target, got, tt.want)
+		}
+	}
+}
+
+// addRequestID is a named middleware for checking MiddlewareNames
+func addRequestID(next http.Handler) http.Handler {
+	return next
+}
+
+func TestMiddlewareNames(t *testing.T) {
+	app := framework.New()
+	app.Use(addRequestID)
+	api := app.Group("/api").Use(allowOrigin)
+	handler.GET(api, "/users", okHandler, func(eo handler.EndpointOptions) {
+		eo.Use(requireToken)
+	})
+
+	want := []string{
+		"framework_test.addRequestID",
+		"framework_test.allowOrigin",
+		"framework_test.requireToken",
+	}
+	if got := framework.MiddlewareNames(app.GetEndpoints()[0]); !slices.Equal(got, want) {
+		t.Errorf("MiddlewareNames() = %q, want %q", got, want)
+	}
+}
+
+func TestGroupClean(t *testing.T) {
+	app := framework.New()
+	account := app.Group("/account").Use(requireToken)
+	handler.GET(account, "/profile", okHandler, func(eo handler.EndpointOptions) {})
+	public := account.GroupClean("/public")
+	handler.GET(public, "/avatar", okHandler, func(eo handler.EndpointOptions) {})
+
+	expectStatus(t, serve(app, httptest.NewRequest(http.MethodGet, "/account/profile", nil)), http.StatusUnauthorized)
+	expectStatus(t, serve(app, httptest.NewRequest(http.MethodGet, "/account/public/avatar", nil)), http.StatusOK)
+}
+
+func TestUseForMethods(t *testing.T) {
+	app := framework.New()
+	api := app.Group("/api").UseForMethods([]string{http.MethodPost, http.MethodPut}, trace("limited"))
+	handler.GET(api, "/items", okHandler, func(eo handler.EndpointOptions) {})
+	handler.POST(api, "/items", okHandler, func(eo handler.EndpointOptions) {})
+	handler.PUT(api, "/items", okHandler, func(eo handler.EndpointOptions) {})
+
+	for method, want := range map[string]bool{http.MethodGet: false, http.MethodPost: true, http.MethodPut: true} {
+		w := serve(app, httptest.NewRequest(method, "/api/items", nil))
+		expectStatus(t, w, http.StatusOK)
+		if ran := w.Header().Get("X-Trace") == "limited"; ran != want {
+			t.Errorf("%s: middleware ran = %v, want %v", method, ran, want)
+		}
+	}
+}
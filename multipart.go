@@ -0,0 +1,170 @@
+package framework
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// isMultipartMixed reports whether r carries a multipart/mixed body
+func isMultipartMixed(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "multipart/mixed"
+}
+
+// parseMultipartMixed parses a multipart/mixed body into r.MultipartForm
+// Each part is keyed by its position ("0", "1", ...) and, when present, by its
+// Content-ID without angle brackets, so Form fields can bind parts either way
+// Parts with a filename become files, all others become text values
+func (f *Framework) parseMultipartMixed(r *http.Request, maxMemory int64) error {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return fmt.Errorf("failed to parse multipart form: %w", err)
+	}
+	boundary := params["boundary"]
+	if boundary == "" {
+		return fmt.Errorf("failed to parse multipart form: missing boundary")
+	}
+
+	// Re-encode the mixed parts as form-data on the fly so the standard
+	// library's ReadForm handles memory limits and temp files for us
+	pr, pw := io.Pipe()
+	formWriter := multipart.NewWriter(pw)
+	contentIDs := make(map[string]string)
+
+	go func() {
+		pw.CloseWithError(f.rewriteMixedParts(multipart.NewReader(r.Body, boundary), formWriter, contentIDs))
+	}()
+
+	form, err := multipart.NewReader(pr, formWriter.Boundary()).ReadForm(maxMemory)
+	pr.Close()
+	if err != nil {
+		return fmt.Errorf("failed to parse multipart form: %w", err)
+	}
+
+	for name, contentID := range contentIDs {
+		if values, ok := form.Value[name]; ok {
+			form.Value[contentID] = values
+		}
+		if files, ok := form.File[name]; ok {
+			form.File[contentID] = files
+		}
+	}
+
+	r.MultipartForm = form
+	return nil
+}
+
+// parseLimitedMultipartForm parses a multipart/form-data body into r.MultipartForm,
+// failing as soon as it holds more than maxMultipartFiles file parts
+func (f *Framework) parseLimitedMultipartForm(r *http.Request, maxMemory int64) error {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return fmt.Errorf("failed to parse multipart form: %w", err)
+	}
+	boundary := params["boundary"]
+	if boundary == "" {
+		return fmt.Errorf("failed to parse multipart form: missing boundary")
+	}
+
+	// Stream the parts through a counter into ReadForm, which handles
+	// memory limits and temp files as usual
+	pr, pw := io.Pipe()
+	formWriter := multipart.NewWriter(pw)
+
+	go func() {
+		pw.CloseWithError(f.copyCountedParts(multipart.NewReader(r.Body, boundary), formWriter))
+	}()
+
+	form, err := multipart.NewReader(pr, formWriter.Boundary()).ReadForm(maxMemory)
+	pr.Close()
+	if err != nil {
+		return fmt.Errorf("failed to parse multipart form: %w", err)
+	}
+
+	r.MultipartForm = form
+	return nil
+}
+
+// copyCountedParts copies every part of src into w unchanged, failing once there are
+// more file parts than maxMultipartFiles
+func (f *Framework) copyCountedParts(src *multipart.Reader, w *multipart.Writer) error {
+	fileCount := 0
+
+	for {
+		part, err := src.NextRawPart()
+		if err == io.EOF {
+			return w.Close()
+		}
+		if err != nil {
+			return err
+		}
+
+		if part.FileName() != "" {
+			fileCount++
+			if fileCount > f.maxMultipartFiles {
+				return fmt.Errorf("too many files in multipart form (max %d)", f.maxMultipartFiles)
+			}
+		}
+
+		dst, err := w.CreatePart(part.Header)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(dst, part); err != nil {
+			return err
+		}
+	}
+}
+
+// rewriteMixedParts copies every part of a multipart/mixed body into w as a form-data part
+// named by its position, recording each part's Content-ID in contentIDs
+func (f *Framework) rewriteMixedParts(mixed *multipart.Reader, w *multipart.Writer, contentIDs map[string]string) error {
+	fileCount := 0
+
+	for i := 0; ; i++ {
+		part, err := mixed.NextPart()
+		if err == io.EOF {
+			return w.Close()
+		}
+		if err != nil {
+			return err
+		}
+
+		name := strconv.Itoa(i)
+		if contentID := strings.Trim(part.Header.Get("Content-ID"), "<>"); contentID != "" {
+			contentIDs[name] = contentID
+		}
+
+		disposition := fmt.Sprintf(`form-data; name="%s"`, name)
+		if filename := part.FileName(); filename != "" {
+			fileCount++
+			if f.maxMultipartFiles > 0 && fileCount > f.maxMultipartFiles {
+				return fmt.Errorf("too many files in multipart form (max %d)", f.maxMultipartFiles)
+			}
+			disposition += fmt.Sprintf(`; filename="%s"`, quoteEscaper.Replace(filename))
+		}
+
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", disposition)
+		if contentType := part.Header.Get("Content-Type"); contentType != "" {
+			header.Set("Content-Type", contentType)
+		}
+
+		dst, err := w.CreatePart(header)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(dst, part); err != nil {
+			return err
+		}
+	}
+}
+
+// quoteEscaper escapes quoted-string values in Content-Disposition headers
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
@@ -0,0 +1,350 @@
+package openapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/RottenNinja-Go/framework"
+	"github.com/RottenNinja-Go/framework/handler"
+	"github.com/RottenNinja-Go/framework/openapi"
+)
+
+// get sends a GET request for target to app
+func get(app http.Handler, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+type Item struct {
+	Name string `json:"name"`
+}
+
+type CreateItemRequest struct {
+	Body struct {
+		Name string `json:"name" validate:"required"`
+	}
+}
+
+// newDocsApp returns an app with one endpoint and the docs registered
+func newDocsApp(t testing.TB, configure func(*framework.Framework, *openapi.OpenApi)) *framework.Framework {
+	t.Helper()
+	app := framework.New()
+	handler.GET(app, "/items", func(ctx context.Context, _ framework.NoRequest) (Item, error) {
+		return Item{Name: "widget"}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	docs := openapi.NewOpenApi(app)
+	if configure != nil {
+		configure(app, docs)
+	}
+	if err := docs.RegisterOpenAPIDocs("Test API", "", "1.0.0", "/openapi.json", "/docs"); err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+func TestDocsSkipEnvelope(t *testing.T) {
+	app := newDocsApp(t, func(app *framework.Framework, _ *openapi.OpenApi) {
+		app.SetResponseEnvelope(func(data any) any {
+			return map[string]any{"data": data}
+		})
+	})
+
+	var wrapped struct {
+		Data Item `json:"data"`
+	}
+	w := get(app, "/items")
+	if err := json.Unmarshal(w.Body.Bytes(), &wrapped); err != nil || wrapped.Data.Name != "widget" {
+		t.Errorf("/items body = %s, want the item wrapped in data", w.Body.String())
+	}
+
+	var spec map[string]any
+	w = get(app, "/openapi.json")
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := spec["data"]; ok {
+		t.Errorf("spec is wrapped in the envelope")
+	}
+	if spec["openapi"] == nil || spec["paths"] == nil {
+		t.Errorf("spec = %s, want a bare OpenAPI document", w.Body.String())
+	}
+}
+
+func TestCachedSpec(t *testing.T) {
+	app := newDocsApp(t, nil)
+	docs := openapi.NewOpenApi(app).Cached()
+
+	first := docs.GenerateOpenAPI("Test API", "", "1.0.0")
+	for range 10 {
+		if spec := docs.GenerateOpenAPI("Test API", "", "1.0.0"); spec != first {
+			t.Fatal("cached spec was regenerated without changes")
+		}
+	}
+
+	handler.GET(app, "/other", func(ctx context.Context, _ framework.NoRequest) (Item, error) {
+		return Item{}, nil
+	}, func(eo handler.EndpointOptions) {})
+	spec := docs.GenerateOpenAPI("Test API", "", "1.0.0")
+	if spec == first {
+		t.Fatal("cached spec was not regenerated after registering an endpoint")
+	}
+	if _, ok := spec.Paths["/other"]; !ok {
+		t.Error("regenerated spec is missing /other")
+	}
+}
+
+type Gadget interface {
+	isGadget()
+}
+
+type Widget struct {
+	Name string `json:"name"`
+}
+
+func (Widget) isGadget() {}
+
+func TestCachedSpecInvalidatedBySetters(t *testing.T) {
+	app := newDocsApp(t, nil)
+	docs := openapi.NewOpenApi(app).Cached()
+
+	tests := []struct {
+		name   string
+		change func()
+		check  func(*openapi.OpenAPISpec) bool
+	}{
+		{"RegisterSchema", func() {
+			docs.RegisterSchema(reflect.TypeFor[Item](), &openapi.Schema{Type: "string"})
+		}, func(spec *openapi.OpenAPISpec) bool {
+			return spec.Paths["/items"].Get.Responses["200"].Content["application/json"].Schema.Type == "string"
+		}},
+		{"RegisterUnion", func() {
+			docs.RegisterUnion(reflect.TypeFor[Gadget](), reflect.TypeFor[Widget]())
+		}, func(spec *openapi.OpenAPISpec) bool {
+			return spec.Components.Schemas["Widget"] != nil
+		}},
+		{"SetServers", func() {
+			docs.SetServers(openapi.Server{URL: "https://api.example.com"})
+		}, func(spec *openapi.OpenAPISpec) bool {
+			return len(spec.Servers) == 1 && spec.Servers[0].URL == "https://api.example.com"
+		}},
+		{"SetSpecCORS", func() {
+			docs.SetSpecCORS("*")
+		}, func(*openapi.OpenAPISpec) bool { return true }},
+	}
+	for _, tt := range tests {
+		before := docs.GenerateOpenAPI("Test API", "", "1.0.0")
+		tt.change()
+		after := docs.GenerateOpenAPI("Test API", "", "1.0.0")
+		if after == before {
+			t.Errorf("%s: cached spec was not regenerated", tt.name)
+			continue
+		}
+		if !tt.check(after) {
+			t.Errorf("%s: regenerated spec doesn't reflect the change", tt.name)
+		}
+	}
+}
+
+func BenchmarkSpecEndpoint(b *testing.B) {
+	for _, cached := range []bool{false, true} {
+		name := "uncached"
+		if cached {
+			name = "cached"
+		}
+		b.Run(name, func(b *testing.B) {
+			app := newDocsApp(b, func(_ *framework.Framework, docs *openapi.OpenApi) {
+				if cached {
+					docs.Cached()
+				}
+			})
+			b.ReportAllocs()
+			for b.Loop() {
+				if w := get(app, "/openapi.json"); w.Code != http.StatusOK {
+					b.Fatalf("status = %d", w.Code)
+				}
+			}
+		})
+	}
+}
+
+// discardWriter is a ResponseWriter that only counts the bytes written and the largest write
+type discardWriter struct {
+	header   http.Header
+	n        int
+	maxWrite int
+}
+
+func (w *discardWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardWriter) Write(p []byte) (int, error) {
+	w.n += len(p)
+	w.maxWrite = max(w.maxWrite, len(p))
+	return len(p), nil
+}
+
+func (w *discardWriter) WriteHeader(int) {}
+
+// newLargeDocsApp returns an app with the given number of endpoints and a cached spec,
+// so serving the spec only measures its encoding
+func newLargeDocsApp(tb testing.TB, endpoints int) *framework.Framework {
+	return newDocsApp(tb, func(app *framework.Framework, docs *openapi.OpenApi) {
+		docs.Cached()
+		for i := range endpoints {
+			handler.GET(app, fmt.Sprintf("/items/%d", i), func(ctx context.Context, _ framework.NoRequest) (Item, error) {
+				return Item{}, nil
+			}, func(eo handler.EndpointOptions) {})
+		}
+	})
+}
+
+// specServingCost serves the spec of app n times, returning its size, the largest single
+// write and the bytes allocated per request for each byte of spec
+func specServingCost(app http.Handler, n int) (specSize, maxWrite int, bytesPerSpecByte float64) {
+	r := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	w := &discardWriter{header: make(http.Header)}
+	app.ServeHTTP(w, r)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	for range n {
+		app.ServeHTTP(&discardWriter{header: make(http.Header)}, r)
+	}
+	runtime.ReadMemStats(&after)
+	return w.n, w.maxWrite, float64(after.TotalAlloc-before.TotalAlloc) / float64(n) / float64(w.n)
+}
+
+// BenchmarkSpecStreaming serves the cached spec of APIs of growing size, reporting the
+// bytes allocated per byte of spec, which stays flat as the spec grows
+func BenchmarkSpecStreaming(b *testing.B) {
+	for _, endpoints := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("endpoints=%d", endpoints), func(b *testing.B) {
+			app := newLargeDocsApp(b, endpoints)
+			b.ReportAllocs()
+			b.ResetTimer()
+			specSize, maxWrite, bytesPerSpecByte := specServingCost(app, b.N)
+			b.ReportMetric(float64(specSize), "spec-bytes")
+			b.ReportMetric(float64(maxWrite), "max-write-bytes")
+			b.ReportMetric(bytesPerSpecByte, "B/spec-byte")
+		})
+	}
+}
+
+func TestSpecStreamingMatchesMarshal(t *testing.T) {
+	var docs *openapi.OpenApi
+	app := newDocsApp(t, func(app *framework.Framework, d *openapi.OpenApi) {
+		docs = d.SetServers(openapi.Server{URL: "https://api.example.com", Description: "<prod>"})
+		handler.POST(app, "/items", func(ctx context.Context, req CreateItemRequest) (Item, error) {
+			return Item{}, nil
+		}, func(eo handler.EndpointOptions) {})
+	})
+
+	want, err := json.Marshal(docs.GenerateOpenAPI("Test API", "", "1.0.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := get(app, "/openapi.json").Body.String(); got != string(want)+"\n" {
+		t.Errorf("streamed spec differs from json.Marshal:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestSpecStreamingBoundedMemory(t *testing.T) {
+	for _, endpoints := range []int{100, 1000} {
+		specSize, maxWrite, bytesPerSpecByte := specServingCost(newLargeDocsApp(t, endpoints), 10)
+		t.Logf("endpoints=%d: spec %d bytes, largest write %d bytes, %.2f B/spec-byte", endpoints, specSize, maxWrite, bytesPerSpecByte)
+
+		// The spec is written a path or schema at a time, never buffered whole
+		if maxWrite > 4<<10 {
+			t.Errorf("endpoints=%d: largest write = %d bytes of a %d byte spec, want at most 4 KiB", endpoints, maxWrite, specSize)
+		}
+		// Allocations grow with the spec only by a small constant factor (about 1 B/byte, more
+		// under the race detector, which defeats buffer pooling)
+		if bytesPerSpecByte > 4 {
+			t.Errorf("endpoints=%d: allocated %.2f bytes per spec byte, want at most 4", endpoints, bytesPerSpecByte)
+		}
+	}
+}
+
+func TestDocsEndpoints(t *testing.T) {
+	app := newDocsApp(t, nil)
+
+	tests := []struct {
+		path, contentType, contains string
+	}{
+		{"/openapi.json", "application/json", `"openapi"`},
+		{"/docs", "text/html", "swagger-ui"},
+	}
+	for _, tt := range tests {
+		w := get(app, tt.path)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s status = %d, want 200", tt.path, w.Code)
+		}
+		if got := w.Header().Get("Content-Type"); got != tt.contentType {
+			t.Errorf("%s Content-Type = %q, want %q", tt.path, got, tt.contentType)
+		}
+		if !strings.Contains(w.Body.String(), tt.contains) {
+			t.Errorf("%s body doesn't contain %q", tt.path, tt.contains)
+		}
+	}
+
+	var spec openapi.OpenAPISpec
+	if err := json.Unmarshal(get(app, "/openapi.json").Body.Bytes(), &spec); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := spec.Paths["/items"]; !ok {
+		t.Error("spec is missing /items")
+	}
+}
+
+func TestSpecCORS(t *testing.T) {
+	fetch := func(app http.Handler, target, origin string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	tests := []struct {
+		name    string
+		origins []string
+		origin  string
+		want    string
+	}{
+		{"disabled", nil, "https://editor.swagger.io", ""},
+		{"allowed origin", []string{"https://editor.swagger.io"}, "https://editor.swagger.io", "https://editor.swagger.io"},
+		{"other origin", []string{"https://editor.swagger.io"}, "https://evil.example", ""},
+		{"any origin", []string{"*"}, "https://evil.example", "*"},
+	}
+	for _, tt := range tests {
+		app := newDocsApp(t, func(_ *framework.Framework, docs *openapi.OpenApi) {
+			docs.SetSpecCORS(tt.origins...)
+		})
+		w := fetch(app, "/openapi.json", tt.origin)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", tt.name, w.Code)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.want {
+			t.Errorf("%s: Access-Control-Allow-Origin = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// Only the spec endpoint gets the header
+	app := newDocsApp(t, func(_ *framework.Framework, docs *openapi.OpenApi) {
+		docs.SetSpecCORS("*")
+	})
+	w := fetch(app, "/items", "https://editor.swagger.io")
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("/items Access-Control-Allow-Origin = %q, want none", got)
+	}
+}
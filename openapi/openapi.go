@@ -1,11 +1,20 @@
 package openapi
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"maps"
+	"mime"
 	"net/http"
 	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/RottenNinja-Go/framework"
 	"github.com/RottenNinja-Go/framework/handler"
@@ -15,6 +24,7 @@ import (
 type OpenAPISpec struct {
 	OpenAPI    string              `json:"openapi"`
 	Info       OpenAPIInfo         `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
 	Paths      map[string]PathItem `json:"paths"`
 	Components *Components         `json:"components,omitempty"`
 }
@@ -26,6 +36,22 @@ type OpenAPIInfo struct {
 	Version     string `json:"version"`
 }
 
+// Server represents a server the API is available on
+// The URL may contain {name} placeholders described by Variables
+// Example: Server{URL: "https://{env}.api.example.com", Variables: map[string]ServerVariable{"env": {Default: "prod", Enum: []string{"prod", "staging"}}}}
+type Server struct {
+	URL         string                    `json:"url"`
+	Description string                    `json:"description,omitempty"`
+	Variables   map[string]ServerVariable `json:"variables,omitempty"`
+}
+
+// ServerVariable represents a substitution for a placeholder in a server URL
+type ServerVariable struct {
+	Enum        []string `json:"enum,omitempty"`
+	Default     string   `json:"default"`
+	Description string   `json:"description,omitempty"`
+}
+
 // PathItem represents operations available on a single path
 type PathItem struct {
 	Get    *Operation `json:"get,omitempty"`
@@ -33,6 +59,68 @@ type PathItem struct {
 	Put    *Operation `json:"put,omitempty"`
 	Patch  *Operation `json:"patch,omitempty"`
 	Delete *Operation `json:"delete,omitempty"`
+
+	// Parameters shared by every operation on the path
+	Parameters []Parameter `json:"parameters,omitempty"`
+}
+
+// operations returns the operations defined on the path
+func (p *PathItem) operations() []*Operation {
+	operations := make([]*Operation, 0, 5)
+	for _, op := range []*Operation{p.Get, p.Post, p.Put, p.Patch, p.Delete} {
+		if op != nil {
+			operations = append(operations, op)
+		}
+	}
+	return operations
+}
+
+// hoistSharedParameters moves parameters declared identically by every operation
+// on the path up to the path level
+func (p *PathItem) hoistSharedParameters() {
+	operations := p.operations()
+	if len(operations) < 2 {
+		return
+	}
+
+	for _, candidate := range operations[0].Parameters {
+		shared := true
+		for _, op := range operations[1:] {
+			if !hasParameter(op.Parameters, candidate) {
+				shared = false
+				break
+			}
+		}
+		if !shared {
+			continue
+		}
+
+		p.Parameters = append(p.Parameters, candidate)
+		for _, op := range operations {
+			op.Parameters = removeParameter(op.Parameters, candidate)
+		}
+	}
+}
+
+// hasParameter reports whether parameters contains an identical parameter
+func hasParameter(parameters []Parameter, param Parameter) bool {
+	for _, existing := range parameters {
+		if reflect.DeepEqual(existing, param) {
+			return true
+		}
+	}
+	return false
+}
+
+// removeParameter returns parameters without the given parameter
+func removeParameter(parameters []Parameter, param Parameter) []Parameter {
+	remaining := make([]Parameter, 0, len(parameters))
+	for _, existing := range parameters {
+		if !reflect.DeepEqual(existing, param) {
+			remaining = append(remaining, existing)
+		}
+	}
+	return remaining
 }
 
 // Operation describes a single API operation
@@ -47,11 +135,13 @@ type Operation struct {
 
 // Parameter describes a single operation parameter
 type Parameter struct {
-	Name        string  `json:"name"`
-	In          string  `json:"in"` // query, header, path
-	Description string  `json:"description,omitempty"`
-	Required    bool    `json:"required"`
-	Schema      *Schema `json:"schema"`
+	Name        string      `json:"name"`
+	In          string      `json:"in"` // query, header, path, cookie
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required"`
+	Deprecated  bool        `json:"deprecated,omitempty"`
+	Schema      *Schema     `json:"schema"`
+	Example     interface{} `json:"example,omitempty"`
 }
 
 // RequestBody describes a single request body
@@ -69,9 +159,16 @@ type MediaType struct {
 // OpenAPIResponse describes a single response in OpenAPI spec
 type OpenAPIResponse struct {
 	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
 	Content     map[string]MediaType `json:"content,omitempty"`
 }
 
+// Header describes a response header
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
 // Schema represents a data type
 type Schema struct {
 	Type       string             `json:"type,omitempty"`
@@ -85,7 +182,25 @@ type Schema struct {
 	Maximum    *float64           `json:"maximum,omitempty"`
 	MinLength  *int               `json:"minLength,omitempty"`
 	MaxLength  *int               `json:"maxLength,omitempty"`
+	MinItems   *int               `json:"minItems,omitempty"`
+	MaxItems   *int               `json:"maxItems,omitempty"`
 	Pattern    string             `json:"pattern,omitempty"`
+	Example    interface{}        `json:"example,omitempty"`
+	Default    interface{}        `json:"default,omitempty"`
+
+	// OneOf and Discriminator describe registered union types
+	OneOf         []*Schema      `json:"oneOf,omitempty"`
+	Discriminator *Discriminator `json:"discriminator,omitempty"`
+
+	// AdditionalProperties is true or a *Schema describing map values
+	AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+}
+
+// Discriminator names the property that tells the members of a oneOf apart
+// Mapping maps each property value to the schema of its member
+type Discriminator struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping,omitempty"`
 }
 
 // Components holds reusable objects
@@ -94,15 +209,249 @@ type Components struct {
 }
 
 func NewOpenApi(f *framework.Framework) *OpenApi {
-	return &OpenApi{f: f}
+	return &OpenApi{
+		f: f,
+		schemaOverrides: map[reflect.Type]*Schema{
+			reflect.TypeFor[framework.JSONPatch](): jsonPatchSchema(),
+			reflect.TypeFor[time.Time]():           {Type: "string", Format: "date-time"},
+		},
+	}
+}
+
+// jsonPatchSchema describes an RFC 6902 JSON Patch document
+func jsonPatchSchema() *Schema {
+	return &Schema{
+		Type: "array",
+		Items: &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				"op": {
+					Type: "string",
+					Enum: []interface{}{"add", "remove", "replace", "move", "copy", "test"},
+				},
+				"path":  {Type: "string"},
+				"from":  {Type: "string"},
+				"value": {},
+			},
+			Required: []string{"op", "path"},
+		},
+	}
 }
 
 type OpenApi struct {
-	f *framework.Framework
+	f               *framework.Framework
+	schemaOverrides map[reflect.Type]*Schema
+	unions          map[reflect.Type][]reflect.Type
+	servers         []Server
+	specOrigins     []string
+
+	// Spec cache, enabled with Cached()
+	cacheEnabled bool
+	cacheMu      sync.Mutex
+	cachedSpec   *OpenAPISpec
+	cachedKey    specCacheKey
+	generation   int // bumped by every setter that changes the spec
+}
+
+// specCacheKey identifies the inputs a cached spec was generated from
+type specCacheKey struct {
+	title         string
+	description   string
+	version       string
+	endpointCount int
+	generation    int
+}
+
+// invalidateCache drops the cached spec after a change to the documentation settings
+// The caller must hold cacheMu
+func (f *OpenApi) invalidateCache() {
+	f.generation++
+	f.cachedSpec = nil
+}
+
+// Cached enables caching of the generated spec
+// The spec is regenerated only when new endpoints are registered, the info changes or a
+// setter such as RegisterSchema or SetServers changes the documentation
+func (f *OpenApi) Cached() *OpenApi {
+	f.cacheEnabled = true
+	return f
+}
+
+// RegisterSchema overrides the generated schema for a Go type
+// The schema is used wherever the type appears, regardless of its kind
+// Example: RegisterSchema(reflect.TypeOf(time.Duration(0)), &Schema{Type: "string", Format: "duration"})
+func (f *OpenApi) RegisterSchema(t reflect.Type, schema *Schema) {
+	f.cacheMu.Lock()
+	defer f.cacheMu.Unlock()
+
+	f.schemaOverrides[t] = schema
+	f.invalidateCache()
+}
+
+// RegisterUnion documents an interface type as a oneOf of the struct types implementing it
+// Each implementation is added to the components, and fields or responses of type iface
+// reference them. Implementations marking a field with a discriminator tag, e.g.
+// Kind string `json:"kind" discriminator:"success"`, make that property the oneOf
+// discriminator; the field must be set to the tag value when responding
+func (f *OpenApi) RegisterUnion(iface reflect.Type, impls ...reflect.Type) {
+	f.cacheMu.Lock()
+	defer f.cacheMu.Unlock()
+
+	if f.unions == nil {
+		f.unions = make(map[reflect.Type][]reflect.Type)
+	}
+	f.unions[iface] = impls
+	f.invalidateCache()
+}
+
+// unionSchema returns the oneOf schema of a registered union
+func (f *OpenApi) unionSchema(impls []reflect.Type) *Schema {
+	schema := &Schema{OneOf: make([]*Schema, 0, len(impls))}
+	for _, impl := range impls {
+		ref := "#/components/schemas/" + unionMemberType(impl).Name()
+		schema.OneOf = append(schema.OneOf, &Schema{Ref: ref})
+
+		if property, value, ok := discriminatorField(impl); ok {
+			if schema.Discriminator == nil {
+				schema.Discriminator = &Discriminator{PropertyName: property, Mapping: make(map[string]string)}
+			}
+			schema.Discriminator.Mapping[value] = ref
+		}
+	}
+	return schema
+}
+
+// addUnionComponents adds the schemas of every registered union member to schemas
+// The discriminator property of each member is restricted to its value
+func (f *OpenApi) addUnionComponents(schemas map[string]*Schema) {
+	for _, impls := range f.unions {
+		for _, impl := range impls {
+			memberSchema := f.structToSchemaInternal(impl)
+			if property, value, ok := discriminatorField(impl); ok && memberSchema.Properties[property] != nil {
+				memberSchema.Properties[property].Enum = []interface{}{value}
+				if !slices.Contains(memberSchema.Required, property) {
+					memberSchema.Required = append(memberSchema.Required, property)
+				}
+			}
+			schemas[unionMemberType(impl).Name()] = memberSchema
+		}
+	}
+}
+
+// unionMemberType returns the struct type of a union member, dereferencing pointers
+func unionMemberType(impl reflect.Type) reflect.Type {
+	if impl.Kind() == reflect.Ptr {
+		return impl.Elem()
+	}
+	return impl
+}
+
+// discriminatorField returns the JSON name and value of the field tagged discriminator in impl
+func discriminatorField(impl reflect.Type) (string, string, bool) {
+	impl = unionMemberType(impl)
+	if impl.Kind() != reflect.Struct {
+		return "", "", false
+	}
+	for i := 0; i < impl.NumField(); i++ {
+		field := impl.Field(i)
+		value, ok := field.Tag.Lookup("discriminator")
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		return name, value, true
+	}
+	return "", "", false
+}
+
+// SetServers sets the servers listed in the spec, replacing any set before
+func (f *OpenApi) SetServers(servers ...Server) *OpenApi {
+	f.cacheMu.Lock()
+	defer f.cacheMu.Unlock()
+
+	f.servers = servers
+	f.invalidateCache()
+	return f
+}
+
+// SetSpecCORS lets browsers on the given origins fetch the spec endpoint, for external
+// doc tools such as hosted Swagger UI or Postman. "*" allows any origin; no origins
+// turns CORS headers off again
+func (f *OpenApi) SetSpecCORS(origins ...string) *OpenApi {
+	f.cacheMu.Lock()
+	defer f.cacheMu.Unlock()
+
+	f.specOrigins = origins
+	f.invalidateCache()
+	return f
+}
+
+// specCORS sets Access-Control-Allow-Origin on spec responses for allowed origins
+func (f *OpenApi) specCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.cacheMu.Lock()
+		origins := f.specOrigins
+		f.cacheMu.Unlock()
+
+		if origin := r.Header.Get("Origin"); origin != "" {
+			for _, allowed := range origins {
+				if allowed == "*" {
+					w.Header().Set("Access-Control-Allow-Origin", "*")
+					break
+				}
+				if allowed == origin {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+					w.Header().Add("Vary", "Origin")
+					break
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// schemaOverride returns a copy of the registered schema for t, if any
+// A copy is returned so validation rules applied per field don't leak between usages
+func (f *OpenApi) schemaOverride(t reflect.Type) (*Schema, bool) {
+	schema, ok := f.schemaOverrides[t]
+	if !ok {
+		return nil, false
+	}
+	schemaCopy := *schema
+	return &schemaCopy, true
 }
 
 // GenerateOpenAPI generates OpenAPI specification
+// When caching is enabled the previously generated spec is reused while the endpoints and
+// the documentation settings are unchanged
 func (f *OpenApi) GenerateOpenAPI(title, description, version string) *OpenAPISpec {
+	if !f.cacheEnabled {
+		return f.generateOpenAPI(title, description, version)
+	}
+
+	f.cacheMu.Lock()
+	defer f.cacheMu.Unlock()
+
+	key := specCacheKey{
+		title:         title,
+		description:   description,
+		version:       version,
+		endpointCount: len(f.f.GetEndpoints()),
+		generation:    f.generation,
+	}
+
+	if f.cachedSpec == nil || f.cachedKey != key {
+		f.cachedSpec = f.generateOpenAPI(title, description, version)
+		f.cachedKey = key
+	}
+	return f.cachedSpec
+}
+
+// generateOpenAPI builds the OpenAPI specification from the registered endpoints
+func (f *OpenApi) generateOpenAPI(title, description, version string) *OpenAPISpec {
 	spec := &OpenAPISpec{
 		OpenAPI: "3.0.0",
 		Info: OpenAPIInfo{
@@ -110,7 +459,8 @@ func (f *OpenApi) GenerateOpenAPI(title, description, version string) *OpenAPISp
 			Description: description,
 			Version:     version,
 		},
-		Paths: make(map[string]PathItem),
+		Servers: f.servers,
+		Paths:   make(map[string]PathItem),
 		Components: &Components{
 			Schemas: make(map[string]*Schema),
 		},
@@ -118,6 +468,10 @@ func (f *OpenApi) GenerateOpenAPI(title, description, version string) *OpenAPISp
 
 	// Generate paths from endpoints
 	for _, endpoint := range f.f.GetEndpoints() {
+		if endpoint.Hidden {
+			continue
+		}
+
 		pathItem, ok := spec.Paths[endpoint.FullPath]
 		if !ok {
 			pathItem = PathItem{}
@@ -141,13 +495,46 @@ func (f *OpenApi) GenerateOpenAPI(title, description, version string) *OpenAPISp
 		spec.Paths[endpoint.FullPath] = pathItem
 	}
 
+	f.addUnionComponents(spec.Components.Schemas)
+
+	// Hoist parameters common to every operation on a path
+	for path, pathItem := range spec.Paths {
+		pathItem.hoistSharedParameters()
+		spec.Paths[path] = pathItem
+	}
+
 	return spec
 }
 
+// bodyTypeWrapper is implemented by response wrappers that send a body of another type
+type bodyTypeWrapper interface {
+	ResponseBodyType() reflect.Type
+}
+
 // generateOperation generates an Operation from an EndpointSpec
 func (f *OpenApi) generateOperation(endpoint *framework.EndpointSpec, schemas map[string]*Schema) *Operation {
-	// Generate response schema from the actual response type
-	responseSchema := f.reflectTypeToSchemaExpanded(endpoint.ResponseType)
+	// Generate response schema from the actual response type, unwrapping wrappers such as
+	// framework.LastModifiedResponse that describe their body type
+	responseType := endpoint.ResponseType
+	wrapper, isWrapper := reflect.Zero(responseType).Interface().(bodyTypeWrapper)
+	if isWrapper {
+		responseType = wrapper.ResponseBodyType()
+	}
+	responseSchema := f.reflectTypeToSchemaExpanded(responseType)
+
+	successContentType := endpoint.Produces
+
+	// File downloads are documented as binary content
+	if endpoint.ResponseType == reflect.TypeFor[framework.FileResponse]() || successContentType == framework.BinaryContentType {
+		responseSchema = &Schema{Type: "string", Format: "binary"}
+		if successContentType == "" {
+			successContentType = framework.BinaryContentType
+		}
+	}
+
+	if successContentType == "" {
+		successContentType = "application/json"
+	}
 
 	operation := &Operation{
 		Summary:     endpoint.Summary,
@@ -155,33 +542,51 @@ func (f *OpenApi) generateOperation(endpoint *framework.EndpointSpec, schemas ma
 		Tags:        endpoint.Tags,
 		Parameters:  make([]Parameter, 0),
 		Responses: map[string]OpenAPIResponse{
-			"200": {
+			strconv.Itoa(successStatus(endpoint)): {
 				Description: "Successful response",
 				Content: map[string]MediaType{
-					"application/json": {
+					successContentType: {
 						Schema: responseSchema,
 					},
 				},
 			},
-			"400": {
-				Description: "Bad request - validation error",
-				Content: map[string]MediaType{
-					"application/json": {
-						Schema: f.getErrorSchema(),
-					},
-				},
-			},
-			"500": {
-				Description: "Internal server error",
-				Content: map[string]MediaType{
-					"application/json": {
-						Schema: f.getErrorSchema(),
-					},
-				},
-			},
+			"400": f.errorResponse("Bad request - validation error"),
+			"500": f.errorResponse("Internal server error"),
 		},
 	}
 
+	// Conditional responses may answer 304 without a body
+	if isWrapper && endpoint.ResponseType.Implements(reflect.TypeFor[framework.RequestResponder]()) {
+		operation.Responses["304"] = OpenAPIResponse{Description: "Not modified"}
+	}
+
+	// Document validation failures separately when they don't use 400
+	if validationStatus := f.f.ValidationStatus(); validationStatus != http.StatusBadRequest {
+		operation.Responses["400"] = f.errorResponse("Bad request - malformed request")
+		operation.Responses[strconv.Itoa(validationStatus)] = f.errorResponse("Validation error")
+	}
+
+	// Document response headers, adding responses for status codes not covered above
+	for code, headers := range endpoint.ResponseHeaders {
+		key := strconv.Itoa(code)
+		response, ok := operation.Responses[key]
+		if !ok {
+			response = OpenAPIResponse{Description: http.StatusText(code)}
+		}
+		response.Headers = make(map[string]Header, len(headers))
+		for name, header := range headers {
+			headerType := header.Type
+			if headerType == "" {
+				headerType = "string"
+			}
+			response.Headers[name] = Header{
+				Description: header.Description,
+				Schema:      &Schema{Type: headerType},
+			}
+		}
+		operation.Responses[key] = response
+	}
+
 	// Parse request type
 	reqType := endpoint.RequestType
 
@@ -201,18 +606,25 @@ func (f *OpenApi) generateOperation(endpoint *framework.EndpointSpec, schemas ma
 		fieldName := field.Name
 		fieldKind := field.Type.Kind()
 
-		// Check if this is a nested struct for Route, Header, Query, Form, or Body
+		// Check if this is a nested struct for Route, Header, Query, Cookie, Form, or Body
 		if fieldKind == reflect.Struct {
 			switch fieldName {
 			case "Route":
 				// Parse nested route parameters
 				f.parseNestedParameters(&operation.Parameters, field.Type, "path")
+				// Drop optional segments this path was registered without
+				operation.Parameters = slices.DeleteFunc(operation.Parameters, func(p Parameter) bool {
+					return p.In == "path" && !hasPathWildcard(endpoint.FullPath, p.Name)
+				})
 			case "Header":
 				// Parse nested header parameters
 				f.parseNestedParameters(&operation.Parameters, field.Type, "header")
 			case "Query":
 				// Parse nested query parameters
 				f.parseNestedParameters(&operation.Parameters, field.Type, "query")
+			case "Cookie":
+				// Parse nested cookie parameters
+				f.parseNestedParameters(&operation.Parameters, field.Type, "cookie")
 			case "Form":
 				// Parse nested form fields
 				hasFormFields = true
@@ -222,7 +634,8 @@ func (f *OpenApi) generateOperation(endpoint *framework.EndpointSpec, schemas ma
 				bodySchema := f.structToSchema(field.Type, schemas)
 				operation.RequestBody = &RequestBody{
 					Description: "Request body",
-					Required:    true,
+					// An empty body decodes to the zero value, so it's only required by required fields
+					Required: len(bodySchema.Required) > 0,
 					Content: map[string]MediaType{
 						"application/json": {
 							Schema: bodySchema,
@@ -231,51 +644,138 @@ func (f *OpenApi) generateOperation(endpoint *framework.EndpointSpec, schemas ma
 				}
 			}
 		}
+
+		// A pointer Body is nil when the request has no body, so it's never required
+		if fieldName == "Body" && fieldKind == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct {
+			operation.RequestBody = &RequestBody{
+				Description: "Request body",
+				Required:    false,
+				Content: map[string]MediaType{
+					"application/json": {
+						Schema: f.structToSchema(field.Type, schemas),
+					},
+				},
+			}
+		}
+
+		// A Body declared as an io.Reader accepts any raw payload
+		if fieldName == "Body" && fieldKind == reflect.Interface && field.Type.NumMethod() > 0 &&
+			reflect.TypeFor[io.ReadCloser]().Implements(field.Type) {
+			operation.RequestBody = &RequestBody{
+				Description: "Raw request body",
+				Required:    true,
+				Content: map[string]MediaType{
+					"application/octet-stream": {
+						Schema: &Schema{Type: "string", Format: "binary"},
+					},
+				},
+			}
+		}
 	}
 
+	bodyRequest := operation.RequestBody
+
 	// If form fields were found, create multipart/form-data request body
+	var formSchema *Schema
 	if hasFormFields {
+		formSchema = &Schema{
+			Type:       "object",
+			Properties: formFields,
+			Required:   formFieldsRequired,
+		}
 		operation.RequestBody = &RequestBody{
 			Description: "Multipart form data",
 			Required:    len(formFieldsRequired) > 0,
 			Content: map[string]MediaType{
-				"multipart/form-data": {
-					Schema: &Schema{
-						Type:       "object",
-						Properties: formFields,
-						Required:   formFieldsRequired,
-					},
-				},
+				"multipart/form-data": {Schema: formSchema},
 			},
 		}
+
+		// Forms without files can be sent URL-encoded as well
+		hasFiles := false
+		for _, fieldSchema := range formFields {
+			if fieldSchema.Format == "binary" || (fieldSchema.Items != nil && fieldSchema.Items.Format == "binary") {
+				hasFiles = true
+			}
+		}
+		if !hasFiles {
+			operation.RequestBody.Description = "Form data"
+			operation.RequestBody.Content["application/x-www-form-urlencoded"] = MediaType{Schema: formSchema}
+		}
+	}
+
+	// Declared content types replace the inferred ones: form types are documented with the
+	// Form schema, all others with the Body schema
+	if len(endpoint.Consumes) > 0 && (bodyRequest != nil || formSchema != nil) {
+		requestBody := &RequestBody{
+			Description: "Request body",
+			Content:     make(map[string]MediaType, len(endpoint.Consumes)),
+		}
+		for _, contentType := range endpoint.Consumes {
+			mediaType, _, _ := mime.ParseMediaType(contentType)
+			isForm := strings.HasPrefix(mediaType, "multipart/") || mediaType == "application/x-www-form-urlencoded"
+			if isForm && formSchema != nil {
+				requestBody.Content[contentType] = MediaType{Schema: formSchema}
+				requestBody.Required = requestBody.Required || len(formFieldsRequired) > 0
+			} else if bodyRequest != nil {
+				for _, media := range bodyRequest.Content {
+					requestBody.Content[contentType] = media
+				}
+				requestBody.Required = requestBody.Required || bodyRequest.Required
+			}
+		}
+		operation.RequestBody = requestBody
 	}
 
 	return operation
 }
 
+// hasPathWildcard reports whether path contains the wildcard {name} or {name...}
+func hasPathWildcard(path, name string) bool {
+	return strings.Contains(path, "{"+name+"}") || strings.Contains(path, "{"+name+"...}")
+}
+
 // parseNestedParameters parses nested struct fields and converts them to OpenAPI parameters
 func (f *OpenApi) parseNestedParameters(parameters *[]Parameter, structType reflect.Type, paramIn string) {
-	for i := 0; i < structType.NumField(); i++ {
-		field := structType.Field(i)
-
-		// Skip unexported fields
-		if !field.IsExported() {
+	for _, field := range framework.NestedFields(structType) {
+		// Catch-all (query:"*") and raw (query:"__raw__") fields aren't named parameters
+		if paramIn == "query" && field.Tag.Get("query") != "" {
 			continue
 		}
 
-		// Get the json tag for the parameter name
-		jsonTag := field.Tag.Get("json")
+		// Get the json tag for the parameter name, without options such as omitempty
+		jsonTag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
 		paramName := field.Name
 		if jsonTag != "" {
 			paramName = jsonTag
 		}
 
+		paramSchema := f.reflectTypeToSchema(field.Type)
+		// Times with a custom layout (format tag) aren't RFC 3339 date-times
+		if layout := field.Tag.Get("format"); layout != "" && paramSchema.Format == "date-time" {
+			paramSchema.Format = ""
+			if layout == time.DateOnly {
+				paramSchema.Format = "date"
+			}
+		}
+		if validateTag := field.Tag.Get("validate"); validateTag != "" {
+			f.applyValidationToSchema(paramSchema, validateTag)
+		}
+		// Defaults are bound when the parameter is absent; array defaults are comma-separated
+		if defaultValue, ok := field.Tag.Lookup("default"); ok {
+			paramSchema.Default = schemaDefault(paramSchema, defaultValue)
+		}
+
 		param := Parameter{
 			Name:        paramName,
 			In:          paramIn,
 			Description: field.Tag.Get("doc"),
 			Required:    strings.Contains(field.Tag.Get("validate"), "required") || paramIn == "path",
-			Schema:      f.reflectTypeToSchema(field.Type),
+			Deprecated:  field.Tag.Get("deprecated") == "true",
+			Schema:      paramSchema,
+		}
+		if example := field.Tag.Get("example"); example != "" {
+			param.Example = enumValue(paramSchema, example)
 		}
 		*parameters = append(*parameters, param)
 	}
@@ -285,16 +785,9 @@ func (f *OpenApi) parseNestedParameters(parameters *[]Parameter, structType refl
 func (f *OpenApi) parseNestedFormFields(formFields *map[string]*Schema, formFieldsRequired *[]string, structType reflect.Type) {
 	fileUploadInterface := reflect.TypeOf((*framework.FileUpload)(nil)).Elem()
 
-	for i := 0; i < structType.NumField(); i++ {
-		field := structType.Field(i)
-
-		// Skip unexported fields
-		if !field.IsExported() {
-			continue
-		}
-
-		// Get the json tag for the field name
-		jsonTag := field.Tag.Get("json")
+	for _, field := range framework.NestedFields(structType) {
+		// Get the json tag for the field name, without options such as omitempty
+		jsonTag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
 		fieldName := field.Name
 		if jsonTag != "" {
 			fieldName = jsonTag
@@ -310,9 +803,19 @@ func (f *OpenApi) parseNestedFormFields(formFields *map[string]*Schema, formFiel
 				Type:   "string",
 				Format: "binary",
 			}
+		} else if field.Type.Kind() == reflect.Slice && field.Type.Elem().Implements(fileUploadInterface) {
+			// Multiple files uploaded under the same name
+			fieldSchema = &Schema{
+				Type:  "array",
+				Items: &Schema{Type: "string", Format: "binary"},
+			}
+			if validateTag := field.Tag.Get("validate"); validateTag != "" {
+				f.applyValidationToSchema(fieldSchema, validateTag)
+			}
 		} else {
 			// Regular form field
 			fieldSchema = f.reflectTypeToSchema(field.Type)
+			applyExample(fieldSchema, field)
 		}
 
 		(*formFields)[fieldName] = fieldSchema
@@ -326,6 +829,18 @@ func (f *OpenApi) parseNestedFormFields(formFields *map[string]*Schema, formFiel
 // reflectTypeToSchema converts a reflect.Type to a Schema
 // This function does NOT expand struct properties - use structToSchema for that
 func (f *OpenApi) reflectTypeToSchema(t reflect.Type) *Schema {
+	// Optional (pointer) values are described by their element type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if override, ok := f.schemaOverride(t); ok {
+		return override
+	}
+	if impls, ok := f.unions[t]; ok {
+		return f.unionSchema(impls)
+	}
+
 	schema := &Schema{}
 
 	switch t.Kind() {
@@ -353,6 +868,14 @@ func (f *OpenApi) reflectTypeToSchema(t reflect.Type) *Schema {
 	case reflect.Slice, reflect.Array:
 		schema.Type = "array"
 		schema.Items = f.reflectTypeToSchemaExpanded(t.Elem())
+	case reflect.Map:
+		schema.Type = "object"
+		// Maps of interface values accept anything, otherwise describe the value type
+		if t.Elem().Kind() == reflect.Interface {
+			schema.AdditionalProperties = true
+		} else {
+			schema.AdditionalProperties = f.reflectTypeToSchemaExpanded(t.Elem())
+		}
 	case reflect.Struct:
 		schema.Type = "object"
 	default:
@@ -368,6 +891,10 @@ func (f *OpenApi) reflectTypeToSchemaExpanded(t reflect.Type) *Schema {
 		t = t.Elem()
 	}
 
+	if override, ok := f.schemaOverride(t); ok {
+		return override
+	}
+
 	// For structs, expand the properties
 	if t.Kind() == reflect.Struct {
 		return f.structToSchemaInternal(t)
@@ -384,6 +911,10 @@ func (f *OpenApi) structToSchemaInternal(t reflect.Type) *Schema {
 		t = t.Elem()
 	}
 
+	if override, ok := f.schemaOverride(t); ok {
+		return override
+	}
+
 	if t.Kind() != reflect.Struct {
 		return f.reflectTypeToSchema(t)
 	}
@@ -422,12 +953,6 @@ func (f *OpenApi) structToSchemaInternal(t reflect.Type) *Schema {
 			fieldSchema = f.reflectTypeToSchemaExpanded(field.Type)
 		} else if field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Struct {
 			fieldSchema = f.reflectTypeToSchema(field.Type) // This will handle the array with expanded items
-		} else if field.Type.Kind() == reflect.Map {
-			// Handle map[string]interface{} and similar types
-			fieldSchema = &Schema{
-				Type: "object",
-			}
-			// If we want to be more specific about the value type, we could inspect field.Type.Elem()
 		} else {
 			fieldSchema = f.reflectTypeToSchema(field.Type)
 		}
@@ -440,6 +965,7 @@ func (f *OpenApi) structToSchemaInternal(t reflect.Type) *Schema {
 				schema.Required = append(schema.Required, fieldName)
 			}
 		}
+		applyExample(fieldSchema, field)
 
 		schema.Properties[fieldName] = fieldSchema
 	}
@@ -453,6 +979,10 @@ func (f *OpenApi) structToSchema(t reflect.Type, schemas map[string]*Schema) *Sc
 		t = t.Elem()
 	}
 
+	if override, ok := f.schemaOverride(t); ok {
+		return override
+	}
+
 	if t.Kind() != reflect.Struct {
 		return f.reflectTypeToSchema(t)
 	}
@@ -485,8 +1015,13 @@ func (f *OpenApi) structToSchema(t reflect.Type, schemas map[string]*Schema) *Sc
 			}
 		}
 
-		// Create field schema
-		fieldSchema := f.reflectTypeToSchema(field.Type)
+		// Create field schema - nested structs are expanded with their own validations
+		var fieldSchema *Schema
+		if field.Type.Kind() == reflect.Struct || (field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct) {
+			fieldSchema = f.reflectTypeToSchemaExpanded(field.Type)
+		} else {
+			fieldSchema = f.reflectTypeToSchema(field.Type)
+		}
 
 		// Add validation info from tags
 		if validateTag := field.Tag.Get("validate"); validateTag != "" {
@@ -502,6 +1037,7 @@ func (f *OpenApi) structToSchema(t reflect.Type, schemas map[string]*Schema) *Sc
 			// OpenAPI doesn't support description in schema properties directly,
 			// but we can add it as a custom field if needed
 		}
+		applyExample(fieldSchema, field)
 
 		schema.Properties[fieldName] = fieldSchema
 	}
@@ -524,6 +1060,10 @@ func (f *OpenApi) applyValidationToSchema(schema *Schema, validateTag string) {
 					var minLen int
 					fmt.Sscanf(parts[1], "%d", &minLen)
 					schema.MinLength = &minLen
+				} else if schema.Type == "array" {
+					var minItems int
+					fmt.Sscanf(parts[1], "%d", &minItems)
+					schema.MinItems = &minItems
 				} else if schema.Type == "number" || schema.Type == "integer" {
 					var min float64
 					fmt.Sscanf(parts[1], "%f", &min)
@@ -536,12 +1076,29 @@ func (f *OpenApi) applyValidationToSchema(schema *Schema, validateTag string) {
 					var maxLen int
 					fmt.Sscanf(parts[1], "%d", &maxLen)
 					schema.MaxLength = &maxLen
+				} else if schema.Type == "array" {
+					var maxItems int
+					fmt.Sscanf(parts[1], "%d", &maxItems)
+					schema.MaxItems = &maxItems
 				} else if schema.Type == "number" || schema.Type == "integer" {
 					var max float64
 					fmt.Sscanf(parts[1], "%f", &max)
 					schema.Maximum = &max
 				}
 			}
+		case "eq":
+			// A single allowed value is documented as a one-element enum
+			if len(parts) > 1 {
+				schema.Enum = []interface{}{enumValue(schema, parts[1])}
+			}
+		case "oneof":
+			// Allowed values are space separated and typed to match the schema (e.g. integer enums)
+			if len(parts) > 1 {
+				schema.Enum = make([]interface{}, 0)
+				for _, value := range strings.Fields(parts[1]) {
+					schema.Enum = append(schema.Enum, enumValue(schema, value))
+				}
+			}
 		case "email":
 			schema.Format = "email"
 		case "url":
@@ -550,6 +1107,117 @@ func (f *OpenApi) applyValidationToSchema(schema *Schema, validateTag string) {
 	}
 }
 
+// applyExample sets the schema example and default from the field's example and default tags
+func applyExample(schema *Schema, field reflect.StructField) {
+	if example := field.Tag.Get("example"); example != "" {
+		schema.Example = enumValue(schema, example)
+	}
+	if defaultValue, ok := field.Tag.Lookup("default"); ok {
+		schema.Default = schemaDefault(schema, defaultValue)
+	}
+}
+
+// schemaDefault converts a default tag value to the JSON type of the schema
+// Array defaults are comma-separated
+func schemaDefault(schema *Schema, defaultValue string) interface{} {
+	if schema.Type != "array" || schema.Items == nil {
+		return enumValue(schema, defaultValue)
+	}
+	var values []interface{}
+	for _, value := range strings.Split(defaultValue, ",") {
+		values = append(values, enumValue(schema.Items, strings.TrimSpace(value)))
+	}
+	return values
+}
+
+// enumValue converts a raw validation value to the JSON type of the schema
+func enumValue(schema *Schema, raw string) interface{} {
+	switch schema.Type {
+	case "integer":
+		if v, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return v
+		}
+	case "number":
+		if v, err := strconv.ParseFloat(raw, 64); err == nil {
+			return v
+		}
+	case "boolean":
+		if v, err := strconv.ParseBool(raw); err == nil {
+			return v
+		}
+	}
+	return raw
+}
+
+// successStatus returns the status code of the endpoint's successful responses
+// Response types implementing StatusCoder document their own code
+func successStatus(endpoint *framework.EndpointSpec) int {
+	if endpoint.ResponseType != nil && endpoint.ResponseType.Implements(reflect.TypeFor[framework.StatusCoder]()) {
+		if endpoint.ResponseType.Kind() != reflect.Interface && endpoint.ResponseType.Kind() != reflect.Pointer {
+			return reflect.Zero(endpoint.ResponseType).Interface().(framework.StatusCoder).StatusCode()
+		}
+	}
+	if endpoint.SuccessStatus != 0 {
+		return endpoint.SuccessStatus
+	}
+	return http.StatusOK
+}
+
+// errorResponse returns an error response in the framework's configured error format
+func (f *OpenApi) errorResponse(description string) OpenAPIResponse {
+	if f.f.ProblemDetails() {
+		return OpenAPIResponse{
+			Description: description,
+			Content: map[string]MediaType{
+				framework.ProblemContentType: {
+					Schema: f.getProblemSchema(),
+				},
+			},
+		}
+	}
+
+	return OpenAPIResponse{
+		Description: description,
+		Content: map[string]MediaType{
+			"application/json": {
+				Schema: f.getErrorSchema(),
+			},
+		},
+	}
+}
+
+// getProblemSchema returns the schema for RFC 7807 problem details responses
+func (f *OpenApi) getProblemSchema() *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"type":   {Type: "string"},
+			"title":  {Type: "string"},
+			"status": {Type: "integer", Format: "int32"},
+			"detail": {Type: "string"},
+			"details": {
+				Type:                 "object",
+				AdditionalProperties: &Schema{Type: "string"},
+			},
+			"fields": {
+				Type: "array",
+				Items: &Schema{
+					Type: "object",
+					Properties: map[string]*Schema{
+						"field":       {Type: "string"},
+						"source_type": {Type: "string"},
+						"errors": {
+							Type:  "array",
+							Items: &Schema{Type: "string"},
+						},
+					},
+				},
+			},
+		},
+		Required: []string{"type", "title", "status"},
+	}
+}
+
 // getErrorSchema returns the schema for error responses
 func (f *OpenApi) getErrorSchema() *Schema {
 	return &Schema{
@@ -569,6 +1237,92 @@ func (f *OpenApi) getErrorSchema() *Schema {
 	}
 }
 
+// SpecResponse streams an OpenAPI spec as JSON to the response writer
+type SpecResponse struct {
+	spec *OpenAPISpec
+}
+
+// WriteResponse implements the Responder interface for the OpenAPI spec
+func (r SpecResponse) WriteResponse(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	encodeSpec(w, r.spec)
+}
+
+// encodeSpec writes spec to w as JSON, producing the same document as json.Marshal
+// Paths and component schemas are encoded one at a time into a reused buffer, so
+// the memory needed is bounded by the largest path or schema rather than the whole spec
+func encodeSpec(w io.Writer, spec *OpenAPISpec) error {
+	e := &specEncoder{w: w}
+	e.enc = json.NewEncoder(&e.buf)
+
+	e.raw(`{"openapi":`)
+	e.value(spec.OpenAPI)
+	e.raw(`,"info":`)
+	e.value(spec.Info)
+	if len(spec.Servers) > 0 {
+		e.raw(`,"servers":`)
+		e.value(spec.Servers)
+	}
+	e.raw(`,"paths":`)
+	encodeMap(e, spec.Paths)
+	if spec.Components != nil {
+		e.raw(`,"components":{`)
+		if len(spec.Components.Schemas) > 0 {
+			e.raw(`"schemas":`)
+			encodeMap(e, spec.Components.Schemas)
+		}
+		e.raw(`}`)
+	}
+	e.raw("}\n")
+	return e.err
+}
+
+// specEncoder writes JSON values to w one at a time, keeping the first error
+type specEncoder struct {
+	w   io.Writer
+	buf bytes.Buffer
+	enc *json.Encoder
+	err error
+}
+
+// raw writes s as-is
+func (e *specEncoder) raw(s string) {
+	if e.err == nil {
+		_, e.err = io.WriteString(e.w, s)
+	}
+}
+
+// value encodes v and writes it without the encoder's trailing newline
+func (e *specEncoder) value(v any) {
+	if e.err != nil {
+		return
+	}
+	e.buf.Reset()
+	if e.err = e.enc.Encode(v); e.err != nil {
+		return
+	}
+	_, e.err = e.w.Write(bytes.TrimSuffix(e.buf.Bytes(), []byte("\n")))
+}
+
+// encodeMap writes m as a JSON object with sorted keys, one entry at a time
+func encodeMap[V any](e *specEncoder, m map[string]V) {
+	if m == nil {
+		e.raw("null")
+		return
+	}
+	e.raw("{")
+	for i, key := range slices.Sorted(maps.Keys(m)) {
+		if i > 0 {
+			e.raw(",")
+		}
+		e.value(key)
+		e.raw(":")
+		e.value(m[key])
+	}
+	e.raw("}")
+}
+
 // SwaggerUIResponse is a custom response that returns HTML
 type SwaggerUIResponse struct {
 	html string
@@ -585,15 +1339,16 @@ func (r SwaggerUIResponse) WriteResponse(w http.ResponseWriter) {
 // This should be called after all other endpoints are registered
 func (f *OpenApi) RegisterOpenAPIDocs(title, description, version, specPath, docsPath string) error {
 	// Register OpenAPI spec endpoint
-	specHandler := func(ctx context.Context, _ framework.NoRequest) (*OpenAPISpec, error) {
-		spec := f.GenerateOpenAPI(title, description, version)
-		return spec, nil
+	specHandler := func(ctx context.Context, _ framework.NoRequest) (SpecResponse, error) {
+		return SpecResponse{spec: f.GenerateOpenAPI(title, description, version)}, nil
 	}
 
 	handler.GET(f.f, specPath, specHandler, func(eo handler.EndpointOptions) {
 		eo.SetSummary("OpenAPI Specification")
 		eo.SetDescription("Returns the OpenAPI 3.0 specification for this API")
 		eo.SetTags("Documentation")
+		eo.SetDisableEnvelope(true)
+		eo.Use(f.specCORS)
 	})
 
 	// Register Swagger UI endpoint
@@ -633,6 +1388,7 @@ func (f *OpenApi) RegisterOpenAPIDocs(title, description, version, specPath, doc
 		eo.SetSummary("API Documentation")
 		eo.SetDescription("Interactive API documentation using Swagger UI")
 		eo.SetTags("Documentation")
+		eo.SetDisableEnvelope(true)
 	})
 
 	return nil
@@ -0,0 +1,823 @@
+package openapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RottenNinja-Go/framework"
+	"github.com/RottenNinja-Go/framework/handler"
+	"github.com/RottenNinja-Go/framework/openapi"
+)
+
+// generate returns the spec of app's endpoints
+func generate(app *framework.Framework) *openapi.OpenAPISpec {
+	return openapi.NewOpenApi(app).GenerateOpenAPI("Test API", "", "1.0.0")
+}
+
+// resolve follows a #/components/schemas reference
+func resolve(t *testing.T, spec *openapi.OpenAPISpec, schema *openapi.Schema) *openapi.Schema {
+	t.Helper()
+	if schema == nil || schema.Ref == "" {
+		return schema
+	}
+	name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+	if spec.Components == nil || spec.Components.Schemas[name] == nil {
+		t.Fatalf("unresolved schema reference %s", schema.Ref)
+	}
+	return spec.Components.Schemas[name]
+}
+
+// bodySchema returns the JSON request body schema of an operation
+func bodySchema(t *testing.T, spec *openapi.OpenAPISpec, op *openapi.Operation) *openapi.Schema {
+	t.Helper()
+	if op == nil || op.RequestBody == nil {
+		t.Fatal("operation has no request body")
+	}
+	return resolve(t, spec, op.RequestBody.Content["application/json"].Schema)
+}
+
+// responseSchema returns the JSON response schema of an operation for a status code
+func responseSchema(t *testing.T, spec *openapi.OpenAPISpec, op *openapi.Operation, code string) *openapi.Schema {
+	t.Helper()
+	response, ok := op.Responses[code]
+	if !ok {
+		t.Fatalf("operation has no %s response", code)
+	}
+	return resolve(t, spec, response.Content["application/json"].Schema)
+}
+
+// findParameter returns the named operation parameter
+func findParameter(t *testing.T, parameters []openapi.Parameter, name string) openapi.Parameter {
+	t.Helper()
+	for _, param := range parameters {
+		if param.Name == name {
+			return param
+		}
+	}
+	t.Fatalf("parameter %q not found in %v", name, parameters)
+	return openapi.Parameter{}
+}
+
+type TagsRequest struct {
+	Body struct {
+		Tags []string `json:"tags" validate:"min=1,max=5"`
+	}
+}
+
+type Empty struct{}
+
+func TestSliceValidationItems(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/tags", func(ctx context.Context, req TagsRequest) (Empty, error) {
+		return Empty{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	spec := generate(app)
+	tags := bodySchema(t, spec, spec.Paths["/tags"].Post).Properties["tags"]
+	if tags == nil || tags.Type != "array" {
+		t.Fatalf("tags schema = %+v, want an array", tags)
+	}
+	if tags.MinItems == nil || *tags.MinItems != 1 || tags.MaxItems == nil || *tags.MaxItems != 5 {
+		t.Errorf("tags minItems/maxItems = %v/%v, want 1/5", tags.MinItems, tags.MaxItems)
+	}
+	if tags.MinLength != nil || tags.MaxLength != nil {
+		t.Errorf("tags has minLength/maxLength, want only item counts")
+	}
+}
+
+type TimeoutRequest struct {
+	Query struct {
+		Timeout time.Duration `json:"timeout"`
+	}
+	Body struct {
+		Timeout  time.Duration   `json:"timeout"`
+		Backoffs []time.Duration `json:"backoffs"`
+	}
+}
+
+func TestRegisterSchema(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/jobs", func(ctx context.Context, req TimeoutRequest) (Empty, error) {
+		return Empty{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	docs := openapi.NewOpenApi(app)
+	docs.RegisterSchema(reflect.TypeFor[time.Duration](), &openapi.Schema{Type: "string", Format: "duration"})
+	spec := docs.GenerateOpenAPI("Test API", "", "1.0.0")
+	op := spec.Paths["/jobs"].Post
+
+	isDuration := func(where string, schema *openapi.Schema) {
+		t.Helper()
+		if schema == nil || schema.Type != "string" || schema.Format != "duration" {
+			t.Errorf("%s schema = %+v, want string/duration", where, schema)
+		}
+	}
+	isDuration("query", findParameter(t, op.Parameters, "timeout").Schema)
+	body := bodySchema(t, spec, op)
+	isDuration("body field", body.Properties["timeout"])
+	if backoffs := body.Properties["backoffs"]; backoffs == nil || backoffs.Type != "array" {
+		t.Fatalf("backoffs schema = %+v, want an array", backoffs)
+	} else {
+		isDuration("slice item", backoffs.Items)
+	}
+}
+
+type JSONOnlyRequest struct {
+	Header struct {
+		ContentType string `json:"Content-Type" validate:"required,eq=application/json"`
+	}
+}
+
+func TestEqValidatorEnum(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/json", func(ctx context.Context, req JSONOnlyRequest) (Empty, error) {
+		return Empty{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	spec := generate(app)
+	param := findParameter(t, spec.Paths["/json"].Post.Parameters, "Content-Type")
+	if param.In != "header" {
+		t.Errorf("Content-Type in = %q, want header", param.In)
+	}
+	if param.Schema == nil || len(param.Schema.Enum) != 1 || param.Schema.Enum[0] != "application/json" {
+		t.Errorf("Content-Type schema = %+v, want enum [application/json]", param.Schema)
+	}
+}
+
+type Metadata struct {
+	Extra  map[string]any    `json:"extra"`
+	Labels map[string]string `json:"labels"`
+}
+
+func TestMapAdditionalProperties(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/metadata", func(ctx context.Context, _ framework.NoRequest) (Metadata, error) {
+		return Metadata{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	spec := generate(app)
+	schema := responseSchema(t, spec, spec.Paths["/metadata"].Get, "200")
+
+	extra := schema.Properties["extra"]
+	if extra == nil || extra.Type != "object" || extra.AdditionalProperties != true {
+		t.Errorf("extra schema = %+v, want an object with additionalProperties true", extra)
+	}
+	labels := schema.Properties["labels"]
+	if labels == nil || labels.Type != "object" {
+		t.Fatalf("labels schema = %+v, want an object", labels)
+	}
+	if values, ok := labels.AdditionalProperties.(*openapi.Schema); !ok || values.Type != "string" {
+		t.Errorf("labels additionalProperties = %+v, want a string schema", labels.AdditionalProperties)
+	}
+}
+
+func TestProblemDetailsContentTypes(t *testing.T) {
+	app := framework.New()
+	app.SetProblemDetails(true)
+	handler.POST(app, "/tags", func(ctx context.Context, req TagsRequest) (Item, error) {
+		return Item{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	spec := generate(app)
+	responses := spec.Paths["/tags"].Post.Responses
+	for code, want := range map[string]string{
+		"200": "application/json",
+		"400": framework.ProblemContentType,
+		"500": framework.ProblemContentType,
+	} {
+		response, ok := responses[code]
+		if !ok {
+			t.Errorf("no %s response", code)
+			continue
+		}
+		if _, ok := response.Content[want]; !ok || len(response.Content) != 1 {
+			t.Errorf("%s response content = %v, want only %s", code, response.Content, want)
+		}
+	}
+}
+
+type GetUserRequest struct {
+	Route struct {
+		ID string `json:"id" validate:"required"`
+	}
+	Header struct {
+		APIKey string `json:"X-API-Key" validate:"required"`
+	}
+	Query struct {
+		Details bool `json:"details"`
+	}
+}
+
+type DeleteUserRequest struct {
+	Route struct {
+		ID string `json:"id" validate:"required"`
+	}
+	Header struct {
+		APIKey string `json:"X-API-Key" validate:"required"`
+	}
+}
+
+func TestHoistSharedParameters(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/users/{id}", func(ctx context.Context, req GetUserRequest) (Item, error) {
+		return Item{}, nil
+	}, func(eo handler.EndpointOptions) {})
+	handler.DELETE(app, "/users/{id}", func(ctx context.Context, req DeleteUserRequest) (Empty, error) {
+		return Empty{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	spec := generate(app)
+	path := spec.Paths["/users/{id}"]
+	if key := findParameter(t, path.Parameters, "X-API-Key"); key.In != "header" || !key.Required {
+		t.Errorf("hoisted X-API-Key = %+v, want a required header", key)
+	}
+	findParameter(t, path.Parameters, "id")
+
+	for method, op := range map[string]*openapi.Operation{"GET": path.Get, "DELETE": path.Delete} {
+		for _, param := range op.Parameters {
+			if param.Name == "X-API-Key" || param.Name == "id" {
+				t.Errorf("%s still declares the shared parameter %s", method, param.Name)
+			}
+		}
+	}
+	// Parameters only one operation declares stay on the operation
+	findParameter(t, path.Get.Parameters, "details")
+}
+
+type SearchRequest struct {
+	Query struct {
+		Q    string `json:"q"`
+		Sort string `json:"sort" deprecated:"true"`
+	}
+}
+
+func TestDeprecatedParameter(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/search", func(ctx context.Context, req SearchRequest) (Empty, error) {
+		return Empty{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	spec := generate(app)
+	op := spec.Paths["/search"].Get
+	if !findParameter(t, op.Parameters, "sort").Deprecated {
+		t.Error("sort is not deprecated")
+	}
+	if findParameter(t, op.Parameters, "q").Deprecated {
+		t.Error("q is deprecated")
+	}
+	var operation map[string]any
+	encoded, _ := json.Marshal(op)
+	if err := json.Unmarshal(encoded, &operation); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := operation["deprecated"]; ok {
+		t.Error("operation is deprecated")
+	}
+}
+
+type Priority int
+
+type CreateTaskRequest struct {
+	Body struct {
+		Title    string   `json:"title" validate:"required"`
+		Priority Priority `json:"priority" validate:"oneof=1 2 3"`
+	}
+}
+
+func TestNumericEnum(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/tasks", func(ctx context.Context, req CreateTaskRequest) (Empty, error) {
+		return Empty{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	r := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(`{"title":"ship it","priority":7}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", w.Code)
+	}
+	var resp framework.ValidationErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Fields) != 1 || resp.Fields[0].Field != "Priority" || resp.Fields[0].SourceType != "body" {
+		t.Errorf("errors = %+v, want one body error for Priority", resp.Fields)
+	}
+
+	spec := generate(app)
+	priority := bodySchema(t, spec, spec.Paths["/tasks"].Post).Properties["priority"]
+	if priority == nil || priority.Type != "integer" {
+		t.Fatalf("priority schema = %+v, want an integer", priority)
+	}
+	want := []interface{}{int64(1), int64(2), int64(3)}
+	if !slices.Equal(priority.Enum, want) {
+		t.Errorf("priority enum = %#v, want %#v", priority.Enum, want)
+	}
+}
+
+type InviteRequest struct {
+	Query struct {
+		Team string `json:"team" example:"platform"`
+	}
+	Body struct {
+		Email string `json:"email" validate:"required,email" example:"a@b.com"`
+		Seats int    `json:"seats" example:"5"`
+	}
+}
+
+func TestExampleTag(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/invites", func(ctx context.Context, req InviteRequest) (Empty, error) {
+		return Empty{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	spec := generate(app)
+	op := spec.Paths["/invites"].Post
+	body := bodySchema(t, spec, op)
+	if email := body.Properties["email"]; email == nil || email.Example != "a@b.com" {
+		t.Errorf("email schema = %+v, want example a@b.com", email)
+	}
+	if seats := body.Properties["seats"]; seats == nil || seats.Example != int64(5) {
+		t.Errorf("seats schema = %+v, want the integer example 5", seats)
+	}
+	if team := findParameter(t, op.Parameters, "team"); team.Example != "platform" {
+		t.Errorf("team parameter = %+v, want example platform", team)
+	}
+}
+
+func TestHiddenEndpoint(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/items", func(ctx context.Context, _ framework.NoRequest) (Item, error) {
+		return Item{}, nil
+	}, func(eo handler.EndpointOptions) {})
+	handler.GET(app, "/admin/stats", func(ctx context.Context, _ framework.NoRequest) (Item, error) {
+		return Item{Name: "stats"}, nil
+	}, func(eo handler.EndpointOptions) {
+		eo.SetHidden(true)
+	})
+
+	spec := generate(app)
+	if _, ok := spec.Paths["/admin/stats"]; ok {
+		t.Error("hidden endpoint is in the spec")
+	}
+	if _, ok := spec.Paths["/items"]; !ok {
+		t.Error("visible endpoint is missing from the spec")
+	}
+
+	if w := get(app, "/admin/stats"); w.Code != http.StatusOK {
+		t.Errorf("hidden endpoint status = %d, want 200", w.Code)
+	}
+}
+
+func TestResponseHeaders(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/items", func(ctx context.Context, _ framework.NoRequest) (Item, error) {
+		return Item{}, nil
+	}, func(eo handler.EndpointOptions) {
+		eo.SetResponseHeaders(http.StatusOK, map[string]framework.HeaderSpec{
+			"X-Rate-Limit-Remaining": {Description: "Requests left in the window", Type: "integer"},
+			"X-Request-Id":           {Description: "Request identifier"},
+		})
+	})
+
+	spec := generate(app)
+	headers := spec.Paths["/items"].Get.Responses["200"].Headers
+	remaining, ok := headers["X-Rate-Limit-Remaining"]
+	if !ok || remaining.Description != "Requests left in the window" || remaining.Schema == nil || remaining.Schema.Type != "integer" {
+		t.Errorf("X-Rate-Limit-Remaining = %+v, want a described integer header", remaining)
+	}
+	if requestID, ok := headers["X-Request-Id"]; !ok || requestID.Schema == nil || requestID.Schema.Type != "string" {
+		t.Errorf("X-Request-Id = %+v, want a string header", requestID)
+	}
+}
+
+type CreatedItem struct {
+	Name string `json:"name"`
+}
+
+func (CreatedItem) StatusCode() int {
+	return http.StatusCreated
+}
+
+func TestSuccessStatusDocumented(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/items", func(ctx context.Context, _ framework.NoRequest) (Item, error) {
+		return Item{}, nil
+	}, func(eo handler.EndpointOptions) {
+		eo.SetSuccessStatus(http.StatusCreated)
+	})
+	handler.POST(app, "/created", func(ctx context.Context, _ framework.NoRequest) (CreatedItem, error) {
+		return CreatedItem{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	spec := generate(app)
+	for _, path := range []string{"/items", "/created"} {
+		responses := spec.Paths[path].Post.Responses
+		if _, ok := responses["200"]; ok {
+			t.Errorf("%s documents 200", path)
+		}
+		responseSchema(t, spec, spec.Paths[path].Post, "201")
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/items", nil))
+	if w.Code != http.StatusCreated {
+		t.Errorf("status = %d, want 201", w.Code)
+	}
+}
+
+type Address struct {
+	Street string `json:"street" validate:"required,max=100"`
+	Zip    string `json:"zip" validate:"required,min=5,max=10"`
+}
+
+type CreateCustomerRequest struct {
+	Body struct {
+		Name    string  `json:"name" validate:"required"`
+		Address Address `json:"address" validate:"required"`
+	}
+}
+
+func TestNestedBodySchema(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/customers", func(ctx context.Context, req CreateCustomerRequest) (Empty, error) {
+		return Empty{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	spec := generate(app)
+	body := bodySchema(t, spec, spec.Paths["/customers"].Post)
+	if !slices.Contains(body.Required, "address") {
+		t.Errorf("body required = %v, want address", body.Required)
+	}
+	address := resolve(t, spec, body.Properties["address"])
+	if address == nil || address.Type != "object" {
+		t.Fatalf("address schema = %+v, want an object", address)
+	}
+	if !slices.Contains(address.Required, "street") || !slices.Contains(address.Required, "zip") {
+		t.Errorf("address required = %v, want street and zip", address.Required)
+	}
+	if street := address.Properties["street"]; street == nil || street.MaxLength == nil || *street.MaxLength != 100 {
+		t.Errorf("street schema = %+v, want maxLength 100", street)
+	}
+	if zip := address.Properties["zip"]; zip == nil || zip.MinLength == nil || *zip.MinLength != 5 || zip.MaxLength == nil || *zip.MaxLength != 10 {
+		t.Errorf("zip schema = %+v, want length 5 to 10", zip)
+	}
+}
+
+func TestBinaryDownload(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/download", func(ctx context.Context, _ framework.NoRequest) (framework.FileResponse, error) {
+		return framework.FileResponse{}, nil
+	}, func(eo handler.EndpointOptions) {})
+	handler.GET(app, "/raw", func(ctx context.Context, _ framework.NoRequest) (framework.Responder, error) {
+		return nil, nil
+	}, func(eo handler.EndpointOptions) {
+		eo.SetProduces(framework.BinaryContentType)
+	})
+
+	spec := generate(app)
+	for _, path := range []string{"/download", "/raw"} {
+		content := spec.Paths[path].Get.Responses["200"].Content
+		media, ok := content[framework.BinaryContentType]
+		if !ok || len(content) != 1 {
+			t.Errorf("%s content = %v, want only %s", path, content, framework.BinaryContentType)
+			continue
+		}
+		if media.Schema == nil || media.Schema.Type != "string" || media.Schema.Format != "binary" {
+			t.Errorf("%s schema = %+v, want a binary string", path, media.Schema)
+		}
+	}
+}
+
+type CountRequest struct {
+	Route struct {
+		Count int `json:"count" validate:"min=1"`
+	}
+}
+
+type CountResponse struct {
+	Count int `json:"count"`
+}
+
+func TestIntegerRouteParam(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/items/{count}", func(ctx context.Context, req CountRequest) (CountResponse, error) {
+		return CountResponse{Count: req.Route.Count}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	w := get(app, "/items/42")
+	if w.Code != http.StatusOK || w.Body.String() != `{"count":42}`+"\n" {
+		t.Errorf("got %d %s, want the count bound as an integer", w.Code, w.Body.String())
+	}
+	if w := get(app, "/items/many"); w.Code != http.StatusBadRequest {
+		t.Errorf("non-numeric count status = %d, want 400", w.Code)
+	}
+
+	spec := generate(app)
+	count := findParameter(t, spec.Paths["/items/{count}"].Get.Parameters, "count")
+	if count.In != "path" || !count.Required || count.Schema == nil || count.Schema.Type != "integer" {
+		t.Errorf("count parameter = %+v, want a required integer path parameter", count)
+	}
+}
+
+func TestServerVariables(t *testing.T) {
+	app := framework.New()
+	docs := openapi.NewOpenApi(app).SetServers(openapi.Server{
+		URL:         "https://{env}.api.example.com",
+		Description: "Per-environment API",
+		Variables: map[string]openapi.ServerVariable{
+			"env": {Default: "prod", Enum: []string{"prod", "staging"}, Description: "Environment"},
+		},
+	})
+
+	encoded, err := json.Marshal(docs.GenerateOpenAPI("Test API", "", "1.0.0").Servers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"url":"https://{env}.api.example.com","description":"Per-environment API","variables":{"env":{"enum":["prod","staging"],"default":"prod","description":"Environment"}}}]`
+	if string(encoded) != want {
+		t.Errorf("servers = %s, want %s", encoded, want)
+	}
+}
+
+func TestNestedGroupPaths(t *testing.T) {
+	app := framework.New()
+	users := app.Group("/api").Group("/v1").Group("/users")
+	handler.GET(users, "/{id}", func(ctx context.Context, req GetUserRequest) (Item, error) {
+		return Item{}, nil
+	}, func(eo handler.EndpointOptions) {})
+	handler.DELETE(users, "/{id}", func(ctx context.Context, req DeleteUserRequest) (Empty, error) {
+		return Empty{}, nil
+	}, func(eo handler.EndpointOptions) {})
+	handler.POST(users, "", func(ctx context.Context, _ framework.NoRequest) (Item, error) {
+		return Item{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	spec := generate(app)
+	if len(spec.Paths) != 2 {
+		t.Errorf("paths = %v, want /api/v1/users and /api/v1/users/{id}", slices.Collect(maps.Keys(spec.Paths)))
+	}
+	if spec.Paths["/api/v1/users"].Post == nil {
+		t.Error("POST /api/v1/users is missing")
+	}
+	byID := spec.Paths["/api/v1/users/{id}"]
+	if byID.Get == nil || byID.Delete == nil {
+		t.Errorf("/api/v1/users/{id} = %+v, want GET and DELETE merged into one path item", byID)
+	}
+}
+
+func TestParameterLocations(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/users/{id}", func(ctx context.Context, req GetUserRequest) (Item, error) {
+		return Item{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	spec := generate(app)
+	params := spec.Paths["/users/{id}"].Get.Parameters
+	tests := []struct {
+		name, in string
+		required bool
+	}{
+		{"id", "path", true},
+		{"X-API-Key", "header", true},
+		{"details", "query", false},
+	}
+	for _, tt := range tests {
+		param := findParameter(t, params, tt.name)
+		if param.In != tt.in || param.Required != tt.required {
+			t.Errorf("%s = in %q required %v, want in %q required %v", tt.name, param.In, param.Required, tt.in, tt.required)
+		}
+	}
+	if len(params) != len(tests) {
+		t.Errorf("parameters = %+v, want %d", params, len(tests))
+	}
+}
+
+type CreateProfileRequest struct {
+	Body struct {
+		Name  string `json:"name" validate:"required"`
+		Email string `json:"email"`
+	}
+}
+
+type UpdateProfileRequest struct {
+	Body struct {
+		Name  string `json:"name" validate:"omitempty,min=3"`
+		Email string `json:"email" validate:"omitempty,email"`
+	}
+}
+
+type ReplaceProfileRequest struct {
+	Body *struct {
+		Name string `json:"name" validate:"required"`
+	}
+}
+
+func TestRequestBodyRequired(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/profiles", func(ctx context.Context, req CreateProfileRequest) (Empty, error) {
+		return Empty{}, nil
+	}, func(eo handler.EndpointOptions) {})
+	handler.PATCH(app, "/profiles/{id}", func(ctx context.Context, req UpdateProfileRequest) (Empty, error) {
+		return Empty{}, nil
+	}, func(eo handler.EndpointOptions) {})
+	handler.PUT(app, "/profiles/{id}", func(ctx context.Context, req ReplaceProfileRequest) (Empty, error) {
+		return Empty{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	spec := generate(app)
+	tests := []struct {
+		name     string
+		op       *openapi.Operation
+		required bool
+	}{
+		{"create", spec.Paths["/profiles"].Post, true},
+		{"update", spec.Paths["/profiles/{id}"].Patch, false},
+		{"pointer body", spec.Paths["/profiles/{id}"].Put, false},
+	}
+	for _, tt := range tests {
+		if tt.op == nil || tt.op.RequestBody == nil {
+			t.Errorf("%s has no request body", tt.name)
+			continue
+		}
+		if tt.op.RequestBody.Required != tt.required {
+			t.Errorf("%s body required = %v, want %v", tt.name, tt.op.RequestBody.Required, tt.required)
+		}
+	}
+}
+
+type SessionRequest struct {
+	Cookie struct {
+		Session string `json:"session" validate:"required"`
+		Theme   string `json:"theme"`
+	}
+}
+
+func TestCookieParameters(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/session", func(ctx context.Context, req SessionRequest) (Item, error) {
+		return Item{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	params := generate(app).Paths["/session"].Get.Parameters
+	for _, tt := range []struct {
+		name     string
+		required bool
+	}{{"session", true}, {"theme", false}} {
+		param := findParameter(t, params, tt.name)
+		if param.In != "cookie" || param.Required != tt.required {
+			t.Errorf("%s = in %q required %v, want in cookie required %v", tt.name, param.In, param.Required, tt.required)
+		}
+	}
+}
+
+type Result interface {
+	isResult()
+}
+
+type Success struct {
+	Kind  string `json:"kind" discriminator:"success"`
+	Value int    `json:"value"`
+}
+
+func (Success) isResult() {}
+
+type Failure struct {
+	Kind   string `json:"kind" discriminator:"failure"`
+	Reason string `json:"reason"`
+}
+
+func (Failure) isResult() {}
+
+type JobResponse struct {
+	Result Result `json:"result"`
+}
+
+func TestRegisterUnion(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/jobs/{id}", func(ctx context.Context, _ framework.NoRequest) (JobResponse, error) {
+		return JobResponse{Result: Success{Kind: "success", Value: 42}}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	docs := openapi.NewOpenApi(app)
+	docs.RegisterUnion(reflect.TypeFor[Result](), reflect.TypeFor[Success](), reflect.TypeFor[*Failure]())
+	spec := docs.GenerateOpenAPI("Test API", "", "1.0.0")
+
+	result := responseSchema(t, spec, spec.Paths["/jobs/{id}"].Get, "200").Properties["result"]
+	if result == nil || len(result.OneOf) != 2 {
+		t.Fatalf("result schema = %+v, want a oneOf of two members", result)
+	}
+	wantMapping := map[string]string{
+		"success": "#/components/schemas/Success",
+		"failure": "#/components/schemas/Failure",
+	}
+	for i, value := range []string{"success", "failure"} {
+		if got := result.OneOf[i].Ref; got != wantMapping[value] {
+			t.Errorf("oneOf[%d] = %q, want %q", i, got, wantMapping[value])
+		}
+	}
+	if result.Discriminator == nil || result.Discriminator.PropertyName != "kind" {
+		t.Fatalf("discriminator = %+v, want property kind", result.Discriminator)
+	}
+	if !reflect.DeepEqual(result.Discriminator.Mapping, wantMapping) {
+		t.Errorf("mapping = %v, want %v", result.Discriminator.Mapping, wantMapping)
+	}
+
+	// Each member restricts the discriminator to its own value
+	for value, ref := range wantMapping {
+		kind := resolve(t, spec, &openapi.Schema{Ref: ref}).Properties["kind"]
+		if kind == nil || !reflect.DeepEqual(kind.Enum, []interface{}{value}) {
+			t.Errorf("%s kind = %+v, want enum [%s]", ref, kind, value)
+		}
+	}
+}
+
+type GalleryRequest struct {
+	Form struct {
+		Photos []framework.FileField `json:"photos"`
+	}
+}
+
+func TestMultipleFilesSchema(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/gallery", func(ctx context.Context, req GalleryRequest) (Empty, error) {
+		return Empty{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	spec := generate(app)
+	media, ok := spec.Paths["/gallery"].Post.RequestBody.Content["multipart/form-data"]
+	if !ok {
+		t.Fatal("request body has no multipart/form-data content")
+	}
+	photos := resolve(t, spec, media.Schema).Properties["photos"]
+	if photos == nil || photos.Type != "array" || photos.Items == nil || photos.Items.Type != "string" || photos.Items.Format != "binary" {
+		t.Errorf("photos schema = %+v, want an array of binary strings", photos)
+	}
+}
+
+type CreatePostRequest struct {
+	Body struct {
+		Title string `json:"title" validate:"required"`
+	}
+	Form struct {
+		Title string              `json:"title" validate:"required"`
+		Cover framework.FileField `json:"cover"`
+	}
+}
+
+func TestConsumes(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/posts", func(ctx context.Context, req CreatePostRequest) (Empty, error) {
+		return Empty{}, nil
+	}, func(eo handler.EndpointOptions) {
+		eo.SetConsumes("application/json", "multipart/form-data")
+	})
+
+	spec := generate(app)
+	body := spec.Paths["/posts"].Post.RequestBody
+	if len(body.Content) != 2 {
+		t.Errorf("content = %v, want JSON and multipart", body.Content)
+	}
+	if !body.Required {
+		t.Error("request body isn't required")
+	}
+	if title := bodySchema(t, spec, spec.Paths["/posts"].Post).Properties["title"]; title == nil {
+		t.Error("JSON schema is missing title")
+	}
+	media, ok := body.Content["multipart/form-data"]
+	if !ok {
+		t.Fatal("request body has no multipart/form-data content")
+	}
+	if cover := resolve(t, spec, media.Schema).Properties["cover"]; cover == nil || cover.Format != "binary" {
+		t.Errorf("multipart cover schema = %+v, want a binary string", cover)
+	}
+}
+
+type TaggedOptionsRequest struct {
+	Route struct {
+		UserID string `json:"id,omitempty" validate:"required"`
+	}
+	Query struct {
+		Verbose bool `json:"verbose,omitempty"`
+	}
+}
+
+func TestParameterTagOptions(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/users/{id}", func(ctx context.Context, req TaggedOptionsRequest) (Item, error) {
+		return Item{}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	params := generate(app).Paths["/users/{id}"].Get.Parameters
+	findParameter(t, params, "id")
+	findParameter(t, params, "verbose")
+}
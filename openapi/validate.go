@@ -0,0 +1,323 @@
+package openapi
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/RottenNinja-Go/framework"
+)
+
+// ValidationBodyLimit is the largest request body ValidatingMiddleware reads
+// Larger bodies are rejected with 413 Payload Too Large
+const ValidationBodyLimit = 10 << 20
+
+// ValidatingMiddleware validates JSON request bodies against the request schema of the
+// matching operation in spec, rejecting mismatches with 400 Bad Request
+// It's experimental and meant for contract testing in staging: it catches drift between
+// the documented schema and what the struct validator accepts. Requests without a
+// documented JSON body pass through untouched
+// The spec must describe the endpoints, so wrap the whole app once they're registered:
+//
+//	spec := docs.GenerateOpenAPI("API", "", "1.0.0")
+//	http.ListenAndServe(":8080", openapi.ValidatingMiddleware(spec)(app))
+func ValidatingMiddleware(spec *OpenAPISpec) framework.Middleware {
+	validator := &specValidator{spec: spec}
+	for template, pathItem := range spec.Paths {
+		validator.paths = append(validator.paths, specPath{
+			segments: strings.Split(template, "/"),
+			item:     pathItem,
+		})
+	}
+	// Like ServeMux, literal segments win over wildcards (/users/me before /users/{id})
+	slices.SortFunc(validator.paths, func(a, b specPath) int {
+		return compareSpecPaths(a.segments, b.segments)
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			schema, required := validator.requestSchema(r)
+			if schema == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, ValidationBodyLimit))
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					writeError(w, http.StatusRequestEntityTooLarge, "request body too large", nil)
+					return
+				}
+				writeValidationError(w, "failed to read request body", nil)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			if len(bytes.TrimSpace(body)) == 0 {
+				if required {
+					writeValidationError(w, "request body is required", nil)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			decoder := json.NewDecoder(bytes.NewReader(body))
+			decoder.UseNumber()
+			var value any
+			if err := decoder.Decode(&value); err != nil {
+				// Malformed JSON is reported by the endpoint's own decoding
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			violations := make(map[string]string)
+			validator.validate(schema, value, "body", violations)
+			if len(violations) > 0 {
+				writeValidationError(w, "request body does not match the API schema", violations)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// specValidator validates request bodies against a spec
+type specValidator struct {
+	spec  *OpenAPISpec
+	paths []specPath
+}
+
+// specPath is a spec path template split into segments
+type specPath struct {
+	segments []string
+	item     PathItem
+}
+
+// requestSchema returns the JSON request body schema of the operation matching r, if any
+func (v *specValidator) requestSchema(r *http.Request) (*Schema, bool) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		return nil, false
+	}
+
+	segments := strings.Split(r.URL.Path, "/")
+	for _, path := range v.paths {
+		if !matchPathSegments(path.segments, segments) {
+			continue
+		}
+
+		var operation *Operation
+		switch r.Method {
+		case http.MethodPost:
+			operation = path.item.Post
+		case http.MethodPut:
+			operation = path.item.Put
+		case http.MethodPatch:
+			operation = path.item.Patch
+		case http.MethodDelete:
+			operation = path.item.Delete
+		}
+		if operation == nil || operation.RequestBody == nil {
+			continue
+		}
+		if mediaType, ok := operation.RequestBody.Content["application/json"]; ok {
+			return mediaType.Schema, operation.RequestBody.Required
+		}
+	}
+	return nil, false
+}
+
+// compareSpecPaths orders path templates so that, segment by segment, literals come
+// before {name} wildcards and those before trailing {name...} wildcards
+func compareSpecPaths(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := cmp.Compare(segmentRank(a[i]), segmentRank(b[i])); c != 0 {
+			return c
+		}
+	}
+	if c := cmp.Compare(len(b), len(a)); c != 0 {
+		return c
+	}
+	return slices.Compare(a, b)
+}
+
+// segmentRank ranks a path template segment by how specific it is, lowest first
+func segmentRank(segment string) int {
+	switch {
+	case segment == "{$}" || !strings.HasPrefix(segment, "{"):
+		return 0
+	case strings.HasSuffix(segment, "...}"):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// matchPathSegments reports whether path segments match template segments, where
+// {name} matches one segment and a trailing {name...} matches the rest
+func matchPathSegments(template, path []string) bool {
+	for i, segment := range template {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "...}") {
+			return true
+		}
+		if i >= len(path) {
+			return false
+		}
+		if segment == "{$}" {
+			return path[i] == "" && i == len(path)-1
+		}
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			continue
+		}
+		if segment != path[i] {
+			return false
+		}
+	}
+	return len(template) == len(path)
+}
+
+// validate checks value against schema, recording violations by JSON path
+func (v *specValidator) validate(schema *Schema, value any, path string, violations map[string]string) {
+	schema = v.resolve(schema)
+	if schema == nil || value == nil {
+		return
+	}
+
+	if len(schema.Enum) > 0 && !enumContains(schema.Enum, value) {
+		violations[path] = "must be one of the allowed values"
+		return
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			violations[path] = "must be an object"
+			return
+		}
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				violations[path+"."+name] = "is required"
+			}
+		}
+		for name, property := range object {
+			if propertySchema, ok := schema.Properties[name]; ok {
+				v.validate(propertySchema, property, path+"."+name, violations)
+			} else if valueSchema, ok := schema.AdditionalProperties.(*Schema); ok {
+				v.validate(valueSchema, property, path+"."+name, violations)
+			}
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			violations[path] = "must be an array"
+			return
+		}
+		if schema.MinItems != nil && len(array) < *schema.MinItems {
+			violations[path] = fmt.Sprintf("must have at least %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(array) > *schema.MaxItems {
+			violations[path] = fmt.Sprintf("must have at most %d items", *schema.MaxItems)
+		}
+		for i, item := range array {
+			v.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), violations)
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			violations[path] = "must be a string"
+			return
+		}
+		length := utf8.RuneCountInString(text)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			violations[path] = fmt.Sprintf("must be at least %d characters", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			violations[path] = fmt.Sprintf("must be at most %d characters", *schema.MaxLength)
+		}
+		if schema.Pattern != "" {
+			if pattern, err := regexp.Compile(schema.Pattern); err == nil && !pattern.MatchString(text) {
+				violations[path] = "must match pattern " + schema.Pattern
+			}
+		}
+	case "integer", "number":
+		kind := "a number"
+		if schema.Type == "integer" {
+			kind = "an integer"
+		}
+		number, ok := value.(json.Number)
+		if !ok {
+			violations[path] = "must be " + kind
+			return
+		}
+		if schema.Type == "integer" {
+			if _, err := number.Int64(); err != nil {
+				violations[path] = "must be " + kind
+				return
+			}
+		}
+		n, err := number.Float64()
+		if err != nil {
+			violations[path] = "must be " + kind
+			return
+		}
+		if schema.Minimum != nil && n < *schema.Minimum {
+			violations[path] = fmt.Sprintf("must be at least %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && n > *schema.Maximum {
+			violations[path] = fmt.Sprintf("must be at most %v", *schema.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			violations[path] = "must be a boolean"
+		}
+	}
+}
+
+// resolve follows a #/components/schemas reference
+func (v *specValidator) resolve(schema *Schema) *Schema {
+	if schema == nil || schema.Ref == "" {
+		return schema
+	}
+	if v.spec.Components == nil {
+		return nil
+	}
+	return v.spec.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+}
+
+// enumContains reports whether value is one of the enum values
+func enumContains(enum []interface{}, value any) bool {
+	for _, allowed := range enum {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeValidationError writes a 400 response in the framework's error shape
+func writeValidationError(w http.ResponseWriter, message string, details map[string]string) {
+	writeError(w, http.StatusBadRequest, message, details)
+}
+
+// writeError writes an error response in the framework's error shape
+func writeError(w http.ResponseWriter, statusCode int, message string, details map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(framework.ErrorResponse{
+		Error:   message,
+		Details: details,
+	})
+}
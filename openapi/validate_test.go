@@ -0,0 +1,90 @@
+package openapi_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RottenNinja-Go/framework"
+	"github.com/RottenNinja-Go/framework/handler"
+	"github.com/RottenNinja-Go/framework/openapi"
+)
+
+type RenameRequest struct {
+	Route struct {
+		ID string `json:"id"`
+	}
+	Body struct {
+		Name string `json:"name" validate:"required,max=5"`
+	}
+}
+
+type UpdateBioRequest struct {
+	Body struct {
+		Bio string `json:"bio" validate:"required,max=20"`
+	}
+}
+
+// newValidatedApp returns an app whose struct validation is off, wrapped in the
+// validating middleware, so only the spec can reject requests
+func newValidatedApp() http.Handler {
+	app := framework.New()
+	skip := func(eo handler.EndpointOptions) {
+		eo.SetSkipValidation(true)
+	}
+	handler.PUT(app, "/users/{id}", func(ctx context.Context, req RenameRequest) (Empty, error) {
+		return Empty{}, nil
+	}, skip)
+	handler.PUT(app, "/users/me", func(ctx context.Context, req UpdateBioRequest) (Empty, error) {
+		return Empty{}, nil
+	}, skip)
+	return openapi.ValidatingMiddleware(generate(app))(app)
+}
+
+// put sends a PUT request with a JSON body to h
+func put(h http.Handler, target, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPut, target, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestValidatingMiddleware(t *testing.T) {
+	h := newValidatedApp()
+
+	if w := put(h, "/users/1", `{"name":"Ada"}`); w.Code != http.StatusOK {
+		t.Errorf("valid body status = %d, want 200 (body: %s)", w.Code, w.Body.String())
+	}
+
+	w := put(h, "/users/1", `{"name":"Augusta"}`)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "name") {
+		t.Errorf("maxLength violation = %d %s, want 400 naming the field", w.Code, w.Body.String())
+	}
+	if w := put(h, "/users/1", `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("missing required field status = %d, want 400", w.Code)
+	}
+}
+
+func TestValidatingMiddlewarePrefersLiteralPaths(t *testing.T) {
+	h := newValidatedApp()
+
+	// /users/me is checked against its own schema, not /users/{id}
+	if w := put(h, "/users/me", `{"bio":"Writes compilers"}`); w.Code != http.StatusOK {
+		t.Errorf("/users/me status = %d, want 200 (body: %s)", w.Code, w.Body.String())
+	}
+	if w := put(h, "/users/me", `{"name":"Ada"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("/users/me without bio status = %d, want 400", w.Code)
+	}
+}
+
+func TestValidatingMiddlewareBodyLimit(t *testing.T) {
+	h := newValidatedApp()
+
+	body := `{"name":"` + strings.Repeat("x", openapi.ValidationBodyLimit) + `"}`
+	if w := put(h, "/users/1", body); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", w.Code)
+	}
+}
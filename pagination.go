@@ -0,0 +1,68 @@
+package framework
+
+// DefaultPageSize is the page size used when a PageRequest doesn't specify one
+const DefaultPageSize = 10
+
+// MaxPageSize is the largest page size accepted by PageRequest
+const MaxPageSize = 100
+
+// PageRequest holds the common pagination query parameters
+// Embed it in a request's Query struct to accept ?page=2&page_size=20:
+//
+//	Query struct {
+//		framework.PageRequest
+//		SortBy string `json:"sort_by"`
+//	}
+type PageRequest struct {
+	Page     int `json:"page" validate:"omitempty,min=1" doc:"Page number (default: 1)"`
+	PageSize int `json:"page_size" validate:"omitempty,min=1,max=100" doc:"Items per page (default: 10, max: 100)"`
+}
+
+// PageNumber returns the requested page, defaulting to 1
+func (p PageRequest) PageNumber() int {
+	if p.Page < 1 {
+		return 1
+	}
+	return p.Page
+}
+
+// Limit returns the requested page size, defaulting to DefaultPageSize
+func (p PageRequest) Limit() int {
+	if p.PageSize < 1 {
+		return DefaultPageSize
+	}
+	if p.PageSize > MaxPageSize {
+		return MaxPageSize
+	}
+	return p.PageSize
+}
+
+// Offset returns the number of items to skip for the requested page
+func (p PageRequest) Offset() int {
+	return (p.PageNumber() - 1) * p.Limit()
+}
+
+// Page is a paginated response carrying one page of items
+type Page[T any] struct {
+	Items      []T `json:"items"`
+	Page       int `json:"page"`
+	PageSize   int `json:"page_size"`
+	TotalItems int `json:"total_items"`
+	TotalPages int `json:"total_pages"`
+}
+
+// NewPage builds a Page from the items of the requested page and the total item count
+func NewPage[T any](items []T, req PageRequest, totalItems int) Page[T] {
+	if items == nil {
+		items = make([]T, 0)
+	}
+
+	pageSize := req.Limit()
+	return Page[T]{
+		Items:      items,
+		Page:       req.PageNumber(),
+		PageSize:   pageSize,
+		TotalItems: totalItems,
+		TotalPages: (totalItems + pageSize - 1) / pageSize,
+	}
+}
@@ -0,0 +1,59 @@
+package framework_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RottenNinja-Go/framework"
+	"github.com/RottenNinja-Go/framework/handler"
+)
+
+type ListNamesRequest struct {
+	Query struct {
+		framework.PageRequest
+		Prefix string `json:"prefix"`
+	}
+}
+
+func TestPagination(t *testing.T) {
+	var names []string
+	for i := range 25 {
+		names = append(names, fmt.Sprintf("name-%02d", i))
+	}
+
+	app := framework.New()
+	handler.GET(app, "/names", func(ctx context.Context, req ListNamesRequest) (framework.Page[string], error) {
+		page := req.Query.PageRequest
+		end := min(page.Offset()+page.Limit(), len(names))
+		return framework.NewPage(names[min(page.Offset(), end):end], page, len(names)), nil
+	}, func(eo handler.EndpointOptions) {})
+
+	tests := []struct {
+		query                    string
+		items, page, size, pages int
+		first                    string
+	}{
+		{"", 10, 1, 10, 3, "name-00"},
+		{"?page=3&page_size=10", 5, 3, 10, 3, "name-20"},
+		{"?page=2&page_size=5&prefix=name", 5, 2, 5, 5, "name-05"},
+	}
+	for _, tt := range tests {
+		w := serve(app, httptest.NewRequest(http.MethodGet, "/names"+tt.query, nil))
+		expectStatus(t, w, http.StatusOK)
+
+		var got framework.Page[string]
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Items) != tt.items || got.Page != tt.page || got.PageSize != tt.size ||
+			got.TotalItems != 25 || got.TotalPages != tt.pages || got.Items[0] != tt.first {
+			t.Errorf("%q: got %+v", tt.query, got)
+		}
+	}
+
+	expectStatus(t, serve(app, httptest.NewRequest(http.MethodGet, "/names?page_size=1000", nil)), http.StatusBadRequest)
+}
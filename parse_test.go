@@ -0,0 +1,64 @@
+package framework_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/RottenNinja-Go/framework"
+)
+
+// CreateUserRequest mirrors the example's create user request
+type CreateUserRequest struct {
+	Header struct {
+		APIKey      string `json:"X-API-Key" validate:"required"`
+		ContentType string `json:"Content-Type" validate:"required,eq=application/json"`
+	}
+	Query struct {
+		DryRun bool     `json:"dry_run"`
+		Tags   []string `json:"tags"`
+	}
+	Body struct {
+		Name  string `json:"name" validate:"required,min=3,max=50"`
+		Email string `json:"email" validate:"required,email"`
+		Age   int    `json:"age" validate:"required,min=18,max=120"`
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/users?tags=a&tags=b", bytes.NewReader([]byte(`{"name":"Alice","email":"alice@example.com","age":30}`)))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("X-API-Key", "secret")
+
+	req, err := framework.ParseRequest[CreateUserRequest](r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Header.APIKey != "secret" || req.Body.Name != "Alice" || req.Body.Age != 30 || len(req.Query.Tags) != 2 {
+		t.Errorf("ParseRequest() = %+v, want the bound request", req)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/users", bytes.NewReader([]byte(`{"name":"Al"}`)))
+	r.Header.Set("Content-Type", "application/json")
+	if _, err := framework.ParseRequest[CreateUserRequest](r); err == nil {
+		t.Error("ParseRequest() accepted an invalid request")
+	}
+}
+
+func FuzzParseRequest(f *testing.F) {
+	f.Add("secret", "application/json", "dry_run=true&tags=a", []byte(`{"name":"Alice","email":"alice@example.com","age":30}`))
+	f.Add("", "application/json", "dry_run=maybe", []byte(`{"age":"thirty"}`))
+	f.Add("key", "text/plain", "tags=%zz", []byte(`{"name":`))
+	f.Add("key", "application/json", "", []byte("\xef\xbb\xbf[1,2,3]"))
+
+	f.Fuzz(func(t *testing.T, apiKey, contentType, query string, body []byte) {
+		r := httptest.NewRequest(http.MethodPost, "/users", bytes.NewReader(body))
+		r.URL.RawQuery = query
+		r.Header.Set("X-API-Key", apiKey)
+		r.Header.Set("Content-Type", contentType)
+
+		// Malformed input must produce an error, never a panic
+		framework.ParseRequest[CreateUserRequest](r)
+	})
+}
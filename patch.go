@@ -0,0 +1,304 @@
+package framework
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONPatchOperation is a single RFC 6902 JSON Patch operation
+type JSONPatchOperation struct {
+	Op    string          `json:"op" validate:"required,oneof=add remove replace move copy test"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch is a request body holding an RFC 6902 JSON Patch document
+// Use it as the Body of a PATCH request and apply it to the stored resource:
+//
+//	type PatchUserRequest struct {
+//		Route struct {
+//			ID string `json:"id"`
+//		}
+//		Body framework.JSONPatch
+//	}
+//
+//	err := req.Body.Apply(&user)
+type JSONPatch struct {
+	Operations []JSONPatchOperation `validate:"dive"`
+}
+
+// UnmarshalJSON decodes a JSON array of patch operations
+func (p *JSONPatch) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(&p.Operations)
+}
+
+// MarshalJSON encodes the patch as a JSON array of operations
+func (p JSONPatch) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.Operations)
+}
+
+// Apply applies the patch operations in order to target, which must be a pointer
+// The target is patched through its JSON representation, so json tags name the paths
+// If any operation fails, target is left unchanged
+func (p JSONPatch) Apply(target any) error {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Pointer || targetValue.IsNil() {
+		return fmt.Errorf("patch target must be a non-nil pointer, got %T", target)
+	}
+
+	original, err := json.Marshal(target)
+	if err != nil {
+		return fmt.Errorf("failed to encode patch target: %w", err)
+	}
+	doc, err := decodeJSONValue(original)
+	if err != nil {
+		return fmt.Errorf("failed to encode patch target: %w", err)
+	}
+
+	for i, op := range p.Operations {
+		doc, err = op.apply(doc)
+		if err != nil {
+			return fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	patched, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode patched document: %w", err)
+	}
+
+	// Decode into a fresh value so removed fields end up zeroed
+	result := reflect.New(targetValue.Elem().Type())
+	if err := json.Unmarshal(patched, result.Interface()); err != nil {
+		return fmt.Errorf("patched document doesn't fit target: %w", err)
+	}
+	targetValue.Elem().Set(result.Elem())
+	return nil
+}
+
+// apply applies the operation to doc and returns the patched document
+func (op JSONPatchOperation) apply(doc any) (any, error) {
+	path, err := parseJSONPointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if len(op.Value) == 0 {
+			return nil, fmt.Errorf("missing value")
+		}
+		value, err := decodeJSONValue(op.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value: %w", err)
+		}
+
+		switch op.Op {
+		case "add":
+			return addJSONValue(doc, path, value)
+		case "replace":
+			if _, err := getJSONValue(doc, path); err != nil {
+				return nil, err
+			}
+			doc, _, err = removeJSONValue(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			return addJSONValue(doc, path, value)
+		default:
+			current, err := getJSONValue(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, fmt.Errorf("test failed")
+			}
+			return doc, nil
+		}
+	case "remove":
+		doc, _, err = removeJSONValue(doc, path)
+		return doc, err
+	case "move", "copy":
+		from, err := parseJSONPointer(op.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from: %w", err)
+		}
+
+		var value any
+		if op.Op == "move" {
+			doc, value, err = removeJSONValue(doc, from)
+		} else {
+			value, err = getJSONValue(doc, from)
+			if err == nil {
+				value, err = deepCopyJSONValue(value)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return addJSONValue(doc, path, value)
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+// decodeJSONValue decodes JSON into a generic value, keeping numbers exact
+func decodeJSONValue(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// deepCopyJSONValue copies a generic JSON value so later operations don't alias it
+func deepCopyJSONValue(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSONValue(data)
+}
+
+// parseJSONPointer splits an RFC 6901 JSON Pointer into unescaped reference tokens
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// getJSONValue returns the value at path
+func getJSONValue(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch container := doc.(type) {
+		case map[string]any:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("path not found")
+			}
+			doc = value
+		case []any:
+			index, err := jsonArrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = container[index]
+		default:
+			return nil, fmt.Errorf("path not found")
+		}
+	}
+	return doc, nil
+}
+
+// addJSONValue adds value at path, inserting into arrays and setting object members
+func addJSONValue(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return updateJSONParent(doc, path, func(parent any, token string) (any, error) {
+		switch container := parent.(type) {
+		case map[string]any:
+			container[token] = value
+			return container, nil
+		case []any:
+			if token == "-" {
+				return append(container, value), nil
+			}
+			index, err := jsonArrayIndex(token, len(container))
+			if err != nil {
+				return nil, err
+			}
+			container = append(container, nil)
+			copy(container[index+1:], container[index:])
+			container[index] = value
+			return container, nil
+		default:
+			return nil, fmt.Errorf("path not found")
+		}
+	})
+}
+
+// removeJSONValue removes the value at path and returns the patched document and removed value
+func removeJSONValue(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the whole document")
+	}
+
+	var removed any
+	doc, err := updateJSONParent(doc, path, func(parent any, token string) (any, error) {
+		switch container := parent.(type) {
+		case map[string]any:
+			value, ok := container[token]
+			if !ok {
+				return nil, fmt.Errorf("path not found")
+			}
+			removed = value
+			delete(container, token)
+			return container, nil
+		case []any:
+			index, err := jsonArrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			removed = container[index]
+			return append(container[:index], container[index+1:]...), nil
+		default:
+			return nil, fmt.Errorf("path not found")
+		}
+	})
+	return doc, removed, err
+}
+
+// updateJSONParent walks to the parent of path's last token, replaces the parent with
+// the result of update and returns the patched document
+func updateJSONParent(doc any, path []string, update func(parent any, token string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return update(doc, path[0])
+	}
+
+	child, err := getJSONValue(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	child, err = updateJSONParent(child, path[1:], update)
+	if err != nil {
+		return nil, err
+	}
+
+	switch container := doc.(type) {
+	case map[string]any:
+		container[path[0]] = child
+	case []any:
+		index, _ := jsonArrayIndex(path[0], len(container)-1)
+		container[index] = child
+	}
+	return doc, nil
+}
+
+// jsonArrayIndex parses an array index token, which must be between 0 and max
+func jsonArrayIndex(token string, max int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	return index, nil
+}
@@ -0,0 +1,64 @@
+package framework_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/RottenNinja-Go/framework"
+	"github.com/RottenNinja-Go/framework/handler"
+)
+
+type Account struct {
+	Name  string   `json:"name"`
+	Email string   `json:"email,omitempty"`
+	Tags  []string `json:"tags"`
+}
+
+type PatchAccountRequest struct {
+	Body framework.JSONPatch
+}
+
+func TestJSONPatchApply(t *testing.T) {
+	app := framework.New()
+	handler.PATCH(app, "/account", func(ctx context.Context, req PatchAccountRequest) (Account, error) {
+		account := Account{Name: "bob", Email: "bob@example.com", Tags: []string{"a"}}
+		err := req.Body.Apply(&account)
+		return account, err
+	}, func(eo handler.EndpointOptions) {})
+
+	w := serve(app, jsonRequest(http.MethodPatch, "/account", `[
+		{"op":"add","path":"/tags/-","value":"b"},
+		{"op":"replace","path":"/name","value":"alice"},
+		{"op":"remove","path":"/email"}
+	]`))
+	expectStatus(t, w, http.StatusOK)
+
+	var got Account
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := Account{Name: "alice", Tags: []string{"a", "b"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("patched account = %+v, want %+v", got, want)
+	}
+
+	// Unknown operations fail validation
+	expectStatus(t, serve(app, jsonRequest(http.MethodPatch, "/account", `[{"op":"bogus","path":"/name"}]`)), http.StatusBadRequest)
+}
+
+func TestJSONPatchLeavesTargetOnFailure(t *testing.T) {
+	var patch framework.JSONPatch
+	if err := json.Unmarshal([]byte(`[{"op":"replace","path":"/name","value":"alice"},{"op":"remove","path":"/missing"}]`), &patch); err != nil {
+		t.Fatal(err)
+	}
+	account := Account{Name: "bob"}
+	if err := patch.Apply(&account); err == nil {
+		t.Fatal("Apply() succeeded with a missing path")
+	}
+	if account.Name != "bob" {
+		t.Errorf("name = %q, want the target unchanged", account.Name)
+	}
+}
@@ -0,0 +1,56 @@
+package framework_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// phaseCounter counts the requests reaching each plugin phase and rejects notes
+// reading "spam" after validation
+type phaseCounter struct {
+	parse, validate, respond int
+}
+
+func (c *phaseCounter) OnParse(ctx context.Context, req any) error {
+	c.parse++
+	return nil
+}
+
+func (c *phaseCounter) OnValidate(ctx context.Context, req any) error {
+	c.validate++
+	if req.(*CreateNoteRequest).Body.Text == "spam" {
+		return errors.New("spam rejected")
+	}
+	return nil
+}
+
+func (c *phaseCounter) OnRespond(ctx context.Context, response any, err error) {
+	c.respond++
+}
+
+func TestPluginPhases(t *testing.T) {
+	counter := &phaseCounter{}
+	app := newNoteApp().UsePlugin(counter)
+
+	tests := []struct {
+		body                     string
+		status                   int
+		parse, validate, respond int
+	}{
+		{`{"text":"hi"}`, http.StatusOK, 1, 1, 1},
+		// Rejected by OnValidate, so the handler doesn't respond
+		{`{"text":"spam"}`, http.StatusBadRequest, 2, 2, 1},
+		// Fails validation before OnValidate
+		{`{}`, http.StatusBadRequest, 3, 2, 1},
+		// Fails parsing before OnParse
+		{`{"text":`, http.StatusBadRequest, 3, 2, 1},
+	}
+	for _, tt := range tests {
+		expectStatus(t, serve(app, jsonRequest(http.MethodPost, "/notes", tt.body)), tt.status)
+		if got := *counter; got != (phaseCounter{tt.parse, tt.validate, tt.respond}) {
+			t.Errorf("after %s: counts = %+v, want parse %d validate %d respond %d", tt.body, got, tt.parse, tt.validate, tt.respond)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package framework_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RottenNinja-Go/framework"
+	"github.com/RottenNinja-Go/framework/handler"
+)
+
+type ItemsRequest struct {
+	Query struct {
+		Items []string `json:"items"`
+	}
+}
+
+func TestIndexedQueryParams(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/items", func(ctx context.Context, req ItemsRequest) ([]string, error) {
+		return req.Query.Items, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"items[1]=b&items[0]=a&items[10]=c", `["a","b","c"]`},
+		// Repeated params keep working
+		{"items=x&items=y", `["x","y"]`},
+	}
+	for _, tt := range tests {
+		w := serve(app, httptest.NewRequest(http.MethodGet, "/items?"+tt.query, nil))
+		expectStatus(t, w, http.StatusOK)
+		if got := w.Body.String(); got != tt.want+"\n" {
+			t.Errorf("%s: body = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+}
+
+type JobFilter struct {
+	Tags  []string `json:"tags"`
+	Limit int      `json:"limit"`
+	Since string   `json:"since"`
+}
+
+func TestDecodeQuery(t *testing.T) {
+	values := url.Values{
+		"tags":  {"urgent", "billing"},
+		"limit": {"25"},
+	}
+
+	var filter JobFilter
+	if err := framework.DecodeQuery(values, &filter); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(filter.Tags, []string{"urgent", "billing"}) || filter.Limit != 25 || filter.Since != "" {
+		t.Errorf("DecodeQuery() = %+v, want tags, limit and an empty since", filter)
+	}
+
+	if err := framework.DecodeQuery(url.Values{"limit": {"many"}}, &filter); err == nil {
+		t.Error("DecodeQuery() accepted a non-numeric limit")
+	}
+	if err := framework.DecodeQuery(values, filter); err == nil {
+		t.Error("DecodeQuery() accepted a non-pointer destination")
+	}
+}
+
+type FilterRequest struct {
+	Query struct {
+		Limit   int               `json:"limit"`
+		Tags    []string          `json:"tags"`
+		Filters map[string]string `query:"*"`
+	}
+}
+
+type FilterResponse struct {
+	Limit   int               `json:"limit"`
+	Tags    []string          `json:"tags"`
+	Filters map[string]string `json:"filters"`
+}
+
+func TestCatchAllQuery(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/products", func(ctx context.Context, req FilterRequest) (FilterResponse, error) {
+		return FilterResponse(req.Query), nil
+	}, func(eo handler.EndpointOptions) {})
+
+	w := serve(app, httptest.NewRequest(http.MethodGet, "/products?limit=3&tags[0]=new&color=red&size=m", nil))
+	expectStatus(t, w, http.StatusOK)
+	if got := w.Body.String(); got != `{"limit":3,"tags":["new"],"filters":{"color":"red","size":"m"}}`+"\n" {
+		t.Errorf("body = %s, want declared params bound and the rest in filters", got)
+	}
+}
+
+type SignedQueryRequest struct {
+	Query struct {
+		Signature string `json:"sig"`
+		Raw       string `query:"__raw__"`
+	}
+}
+
+type SignedQueryResponse struct {
+	Signature string `json:"sig"`
+	Raw       string `json:"raw"`
+}
+
+func TestRawQuery(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/callback", func(ctx context.Context, req SignedQueryRequest) (SignedQueryResponse, error) {
+		return SignedQueryResponse(req.Query), nil
+	}, func(eo handler.EndpointOptions) {})
+
+	const raw = "z=1&a=%20x&sig=abc&a=2"
+	w := serve(app, httptest.NewRequest(http.MethodGet, "/callback?"+raw, nil))
+	expectStatus(t, w, http.StatusOK)
+
+	var got SignedQueryResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Raw != raw || got.Signature != "abc" {
+		t.Errorf("got %+v, want raw %q verbatim and sig abc", got, raw)
+	}
+}
+
+type EventsRequest struct {
+	Query struct {
+		From time.Time  `json:"from"`
+		Day  *time.Time `json:"day" format:"2006-01-02"`
+	}
+}
+
+type EventsResponse struct {
+	From string `json:"from"`
+	Day  string `json:"day"`
+}
+
+func TestTimeQueryParams(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/events", func(ctx context.Context, req EventsRequest) (EventsResponse, error) {
+		var resp EventsResponse
+		if !req.Query.From.IsZero() {
+			resp.From = req.Query.From.Format(time.RFC3339)
+		}
+		if req.Query.Day != nil {
+			resp.Day = req.Query.Day.Format(time.DateOnly)
+		}
+		return resp, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	w := serve(app, httptest.NewRequest(http.MethodGet, "/events?from=2024-01-02T03:04:05Z&day=2024-05-06", nil))
+	expectStatus(t, w, http.StatusOK)
+	if got := w.Body.String(); got != `{"from":"2024-01-02T03:04:05Z","day":"2024-05-06"}`+"\n" {
+		t.Errorf("body = %s, want both times bound", got)
+	}
+
+	// Invalid values fail with the field name; the custom layout replaces RFC 3339
+	for _, query := range []string{"from=yesterday", "day=2024-05-06T00:00:00Z"} {
+		w := serve(app, httptest.NewRequest(http.MethodGet, "/events?"+query, nil))
+		expectStatus(t, w, http.StatusBadRequest)
+		name := strings.SplitN(query, "=", 2)[0]
+		if !strings.Contains(w.Body.String(), "'"+name+"'") {
+			t.Errorf("%s: body = %s, want the field name", query, w.Body.String())
+		}
+	}
+}
+
+type ListUsersRequest struct {
+	Query struct {
+		Page   int     `json:"page" default:"1" validate:"min=1"`
+		Limit  int     `json:"limit" default:"10" validate:"max=100"`
+		Sort   string  `json:"sort" default:"name"`
+		Active bool    `json:"active" default:"true"`
+		Ratio  float64 `json:"ratio" default:"0.5"`
+	}
+	Header struct {
+		Locale string `json:"Accept-Language" default:"en"`
+	}
+}
+
+type ListUsersResponse struct {
+	Page   int     `json:"page"`
+	Limit  int     `json:"limit"`
+	Sort   string  `json:"sort"`
+	Active bool    `json:"active"`
+	Ratio  float64 `json:"ratio"`
+	Locale string  `json:"locale"`
+}
+
+func TestQueryDefaults(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/users", func(ctx context.Context, req ListUsersRequest) (ListUsersResponse, error) {
+		q := req.Query
+		return ListUsersResponse{q.Page, q.Limit, q.Sort, q.Active, q.Ratio, req.Header.Locale}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", `{"page":1,"limit":10,"sort":"name","active":true,"ratio":0.5,"locale":"en"}`},
+		{"?page=3&limit=50&sort=age&active=false&ratio=2", `{"page":3,"limit":50,"sort":"age","active":false,"ratio":2,"locale":"en"}`},
+	}
+	for _, tt := range tests {
+		w := serve(app, httptest.NewRequest(http.MethodGet, "/users"+tt.query, nil))
+		expectStatus(t, w, http.StatusOK)
+		if got := w.Body.String(); got != tt.want+"\n" {
+			t.Errorf("%q: body = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+
+	// Explicit values are still validated
+	expectStatus(t, serve(app, httptest.NewRequest(http.MethodGet, "/users?limit=500", nil)), http.StatusBadRequest)
+}
+
+type BadQueryDefaultRequest struct {
+	Query struct {
+		Page int `json:"page" default:"first"`
+	}
+}
+
+func TestInvalidQueryDefault(t *testing.T) {
+	app := framework.New()
+	message := registrationPanic(func() {
+		handler.GET(app, "/users", func(ctx context.Context, req BadQueryDefaultRequest) (UploadResponse, error) {
+			return UploadResponse{}, nil
+		}, func(eo handler.EndpointOptions) {})
+	})
+	if !strings.Contains(message, "page") || !strings.Contains(message, "first") {
+		t.Errorf("panic = %q, want the invalid default reported", message)
+	}
+}
+
+type SortRequest struct {
+	Query struct {
+		Order  string   `json:"order" normalize:"lower" validate:"omitempty,oneof=asc desc"`
+		Fields []string `json:"fields" normalize:"trim"`
+	}
+}
+
+type SortResponse struct {
+	Order  string   `json:"order"`
+	Fields []string `json:"fields"`
+}
+
+func TestNormalizeTag(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/items", func(ctx context.Context, req SortRequest) (SortResponse, error) {
+		return SortResponse{Order: req.Query.Order, Fields: req.Query.Fields}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	for _, order := range []string{"ASC", "Asc", "asc"} {
+		w := serve(app, httptest.NewRequest(http.MethodGet, "/items?order="+order, nil))
+		expectStatus(t, w, http.StatusOK)
+		if want := `{"order":"asc","fields":null}` + "\n"; w.Body.String() != want {
+			t.Errorf("order=%s: body = %s, want %s", order, w.Body.String(), want)
+		}
+	}
+
+	w := serve(app, httptest.NewRequest(http.MethodGet, "/items?fields=+name+&fields=age%20", nil))
+	expectStatus(t, w, http.StatusOK)
+	if want := `{"order":"","fields":["name","age"]}` + "\n"; w.Body.String() != want {
+		t.Errorf("body = %s, want %s", w.Body.String(), want)
+	}
+
+	// Normalized values are still validated
+	expectStatus(t, serve(app, httptest.NewRequest(http.MethodGet, "/items?order=UP", nil)), http.StatusBadRequest)
+}
+
+type UnknownNormalizeRequest struct {
+	Query struct {
+		Order string `json:"order" normalize:"title"`
+	}
+}
+
+func TestUnknownNormalize(t *testing.T) {
+	app := framework.New()
+	message := registrationPanic(func() {
+		handler.GET(app, "/items", func(ctx context.Context, req UnknownNormalizeRequest) (SortResponse, error) {
+			return SortResponse{}, nil
+		}, func(eo handler.EndpointOptions) {})
+	})
+	if !strings.Contains(message, `"title"`) {
+		t.Errorf("panic = %q, want the unknown normalize reported", message)
+	}
+}
+
+func TestInvalidBoolQuery(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/users", func(ctx context.Context, req ListUsersRequest) (ListUsersResponse, error) {
+		return ListUsersResponse{Active: req.Query.Active}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	for _, value := range []string{"true", "1", "T", "false", "0", "F"} {
+		expectStatus(t, serve(app, httptest.NewRequest(http.MethodGet, "/users?active="+value, nil)), http.StatusOK)
+	}
+
+	for _, value := range []string{"abc", "yes", "2"} {
+		w := serve(app, httptest.NewRequest(http.MethodGet, "/users?active="+value, nil))
+		expectStatus(t, w, http.StatusBadRequest)
+		if !strings.Contains(w.Body.String(), "active") || !strings.Contains(w.Body.String(), "invalid boolean value") {
+			t.Errorf("active=%s: body = %s, want the invalid boolean reported", value, w.Body.String())
+		}
+	}
+}
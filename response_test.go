@@ -0,0 +1,332 @@
+package framework_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/RottenNinja-Go/framework"
+	"github.com/RottenNinja-Go/framework/handler"
+	"github.com/RottenNinja-Go/framework/middleware"
+)
+
+type CreatedUser struct {
+	ID int `json:"id"`
+}
+
+func (CreatedUser) StatusCode() int {
+	return http.StatusCreated
+}
+
+func TestResponseStatusCode(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/users", func(ctx context.Context, _ framework.NoRequest) (CreatedUser, error) {
+		return CreatedUser{ID: 1}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	w := serve(app, httptest.NewRequest(http.MethodPost, "/users", nil))
+	expectStatus(t, w, http.StatusCreated)
+	if body := w.Body.String(); body != "{\"id\":1}\n" {
+		t.Errorf("body = %q, want the user", body)
+	}
+}
+
+func TestTextResponses(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/responder", func(ctx context.Context, _ framework.NoRequest) (framework.Responder, error) {
+		return framework.TextResponse(http.StatusAccepted, "queued"), nil
+	}, func(eo handler.EndpointOptions) {})
+	handler.GET(app, "/ping", func(ctx context.Context, _ framework.NoRequest) (string, error) {
+		return "pong", nil
+	}, func(eo handler.EndpointOptions) {
+		eo.SetProduces("text/plain")
+	})
+	handler.GET(app, "/json", func(ctx context.Context, _ framework.NoRequest) (string, error) {
+		return "pong", nil
+	}, func(eo handler.EndpointOptions) {})
+
+	tests := []struct {
+		path        string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"/responder", http.StatusAccepted, "text/plain; charset=utf-8", "queued"},
+		{"/ping", http.StatusOK, "text/plain; charset=utf-8", "pong"},
+		// Without text/plain a string is still encoded as JSON
+		{"/json", http.StatusOK, "application/json", "\"pong\"\n"},
+	}
+	for _, tt := range tests {
+		w := serve(app, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		expectStatus(t, w, tt.status)
+		if got := w.Header().Get("Content-Type"); got != tt.contentType {
+			t.Errorf("%s: Content-Type = %q, want %q", tt.path, got, tt.contentType)
+		}
+		if got := w.Body.String(); got != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.path, got, tt.body)
+		}
+	}
+}
+
+func TestPrewrittenStatus(t *testing.T) {
+	app := framework.New()
+	accepted := app.Group("").Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			next.ServeHTTP(w, r)
+		})
+	})
+	handler.GET(accepted, "/jobs", okHandler, func(eo handler.EndpointOptions) {})
+
+	var logs bytes.Buffer
+	server := httptest.NewUnstartedServer(app)
+	server.Config.ErrorLog = log.New(&logs, "", 0)
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("status = %d, want the middleware's 202", resp.StatusCode)
+	}
+	if strings.Contains(logs.String(), "superfluous") {
+		t.Errorf("server logged %q", logs.String())
+	}
+}
+
+// countdown streams three lines, waiting for next before producing each one
+func countdown(next <-chan struct{}) framework.ChunkedResponse {
+	return framework.ChunkedResponse{Stream: func(write func(string) error) error {
+		for i := range 3 {
+			if next != nil {
+				<-next
+			}
+			if err := write(fmt.Sprintf("step %d", i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}}
+}
+
+func TestChunkedResponse(t *testing.T) {
+	next := make(chan struct{})
+	app := framework.New()
+	handler.GET(app, "/progress", func(ctx context.Context, _ framework.NoRequest) (framework.Responder, error) {
+		return countdown(next), nil
+	}, func(eo handler.EndpointOptions) {})
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	go func() { next <- struct{}{} }()
+	resp, err := http.Get(server.URL + "/progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// Each line is read before the handler is allowed to produce the next one
+	lines := bufio.NewScanner(resp.Body)
+	for i := range 3 {
+		if !lines.Scan() {
+			t.Fatalf("stream ended after %d lines: %v", i, lines.Err())
+		}
+		if want := fmt.Sprintf("step %d", i); lines.Text() != want {
+			t.Errorf("line %d = %q, want %q", i, lines.Text(), want)
+		}
+		if i < 2 {
+			next <- struct{}{}
+		}
+	}
+	if lines.Scan() {
+		t.Errorf("unexpected line %q after the stream completed", lines.Text())
+	}
+}
+
+func TestChunkedResponseBehindTimeout(t *testing.T) {
+	app := framework.New()
+	app.Use(middleware.Timeout(time.Second))
+	handler.GET(app, "/progress", func(ctx context.Context, _ framework.NoRequest) (framework.Responder, error) {
+		return countdown(nil), nil
+	}, func(eo handler.EndpointOptions) {})
+
+	// The buffered lines all arrive once the stream completes
+	w := serve(app, httptest.NewRequest(http.MethodGet, "/progress", nil))
+	expectStatus(t, w, http.StatusOK)
+	if got := w.Body.String(); got != "step 0\nstep 1\nstep 2\n" {
+		t.Errorf("body = %q, want all three lines", got)
+	}
+}
+
+func TestStrictAccept(t *testing.T) {
+	app := framework.New()
+	app.SetStrictAccept(true)
+	handler.GET(app, "/items", okHandler, func(eo handler.EndpointOptions) {})
+
+	tests := []struct {
+		accept string
+		want   int
+	}{
+		{"", http.StatusOK},
+		{"application/json", http.StatusOK},
+		{"*/*", http.StatusOK},
+		{"application/*", http.StatusOK},
+		{"text/html, application/json;q=0.5", http.StatusOK},
+		{"application/xml", http.StatusNotAcceptable},
+		{"application/json;q=0, text/html", http.StatusNotAcceptable},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/items", nil)
+		if tt.accept != "" {
+			r.Header.Set("Accept", tt.accept)
+		}
+		w := serve(app, r)
+		if w.Code != tt.want {
+			t.Errorf("Accept %q: status = %d, want %d", tt.accept, w.Code, tt.want)
+		}
+		if tt.want == http.StatusNotAcceptable && w.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("Accept %q: Content-Type = %q, want a JSON error", tt.accept, w.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestIndent(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/note", func(ctx context.Context, _ framework.NoRequest) (Note, error) {
+		return Note{Text: "hi"}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	w := serve(app, httptest.NewRequest(http.MethodGet, "/note", nil))
+	if got := w.Body.String(); got != `{"text":"hi"}`+"\n" {
+		t.Errorf("default body = %q, want compact JSON", got)
+	}
+
+	app.SetIndent("", "  ")
+	w = serve(app, httptest.NewRequest(http.MethodGet, "/note", nil))
+	if got := w.Body.String(); got != "{\n  \"text\": \"hi\"\n}\n" {
+		t.Errorf("indented body = %q, want pretty-printed JSON", got)
+	}
+}
+
+func TestLastModifiedResponse(t *testing.T) {
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	app := framework.New()
+	handler.GET(app, "/note", func(ctx context.Context, _ framework.NoRequest) (framework.LastModifiedResponse[Note], error) {
+		return framework.LastModifiedResponse[Note]{ModTime: modTime, Body: Note{Text: "hi"}}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	request := func(ifModifiedSince time.Time) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/note", nil)
+		if !ifModifiedSince.IsZero() {
+			r.Header.Set("If-Modified-Since", ifModifiedSince.Format(http.TimeFormat))
+		}
+		return serve(app, r)
+	}
+
+	w := request(time.Time{})
+	expectStatus(t, w, http.StatusOK)
+	if got := w.Header().Get("Last-Modified"); got != modTime.Format(http.TimeFormat) {
+		t.Errorf("Last-Modified = %q, want %q", got, modTime.Format(http.TimeFormat))
+	}
+	if got := w.Body.String(); got != `{"text":"hi"}`+"\n" {
+		t.Errorf("body = %s, want the note", got)
+	}
+
+	w = request(modTime.Add(time.Hour))
+	expectStatus(t, w, http.StatusNotModified)
+	if w.Body.Len() != 0 {
+		t.Errorf("304 body = %q, want none", w.Body.String())
+	}
+
+	expectStatus(t, request(modTime.Add(-time.Hour)), http.StatusOK)
+}
+
+func TestLastModifiedResponseEncoding(t *testing.T) {
+	app := framework.New()
+	app.SetIndent("", "  ")
+	app.SetResponseEnvelope(func(data any) any {
+		return map[string]any{"data": data}
+	})
+	handler.GET(app, "/note", func(ctx context.Context, _ framework.NoRequest) (framework.LastModifiedResponse[Note], error) {
+		return framework.LastModifiedResponse[Note]{ModTime: time.Unix(0, 0), Body: Note{Text: "hi"}}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	// The body goes through the same envelope and encoder as other responses
+	w := serve(app, httptest.NewRequest(http.MethodGet, "/note", nil))
+	expectStatus(t, w, http.StatusOK)
+	if got, want := w.Body.String(), "{\n  \"data\": {\n    \"text\": \"hi\"\n  }\n}\n"; got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+// errorRecorder is a RespondPlugin keeping the last error passed to it
+type errorRecorder struct {
+	err error
+}
+
+func (p *errorRecorder) OnRespond(ctx context.Context, response any, err error) {
+	p.err = err
+}
+
+// statusResponder is a pointer Responder writing its status
+type statusResponder struct {
+	status int
+}
+
+func (s *statusResponder) WriteResponse(w http.ResponseWriter) {
+	w.WriteHeader(s.status)
+}
+
+func TestResponderWithWarning(t *testing.T) {
+	plugin := &errorRecorder{}
+	app := framework.New().UsePlugin(plugin)
+	handler.POST(app, "/import", func(ctx context.Context, _ framework.NoRequest) (framework.Responder, error) {
+		return framework.TextResponse(http.StatusOK, "9 of 10 rows imported"), &framework.Warning{Message: "row 4 skipped"}
+	}, func(eo handler.EndpointOptions) {})
+
+	w := serve(app, httptest.NewRequest(http.MethodPost, "/import", nil))
+	expectStatus(t, w, http.StatusOK)
+	if got := w.Body.String(); got != "9 of 10 rows imported" {
+		t.Errorf("body = %q, want the Responder's output", got)
+	}
+	if got := w.Header().Get("Warning"); got != `299 - "row 4 skipped"` {
+		t.Errorf("Warning = %q, want the warning message", got)
+	}
+	var warning *framework.Warning
+	if !errors.As(plugin.err, &warning) {
+		t.Errorf("plugin got %v, want the warning", plugin.err)
+	}
+}
+
+func TestWarningWithoutResponder(t *testing.T) {
+	app := framework.New()
+	handler.POST(app, "/import", func(ctx context.Context, _ framework.NoRequest) (*statusResponder, error) {
+		return nil, &framework.Warning{Message: "nothing imported"}
+	}, func(eo handler.EndpointOptions) {})
+	handler.POST(app, "/other", func(ctx context.Context, _ framework.NoRequest) (Note, error) {
+		return Note{}, &framework.Warning{Message: "not a responder"}
+	}, func(eo handler.EndpointOptions) {})
+
+	// A nil Responder or a plain response can't be written, so the warning is an error
+	for _, path := range []string{"/import", "/other"} {
+		w := serve(app, httptest.NewRequest(http.MethodPost, path, nil))
+		expectStatus(t, w, http.StatusInternalServerError)
+		if w.Header().Get("Warning") != "" {
+			t.Errorf("%s: Warning header set on an error response", path)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package framework
+
+import (
+	"context"
+	"net/http"
+)
+
+// responseStateContextKey is the context key used to store the request's responseState
+type responseStateContextKey struct{}
+
+// responseState wraps the ResponseWriter handed to an endpoint's middleware chain
+// and records whether the response has been committed
+type responseState struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+// trackResponse wraps next so the handler can tell whether a middleware already wrote the response
+func trackResponse(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := &responseState{ResponseWriter: w}
+		ctx := context.WithValue(r.Context(), responseStateContextKey{}, state)
+		next.ServeHTTP(state, r.WithContext(ctx))
+	})
+}
+
+// WriteHeader forwards the status code once and drops any later calls
+func (s *responseState) WriteHeader(statusCode int) {
+	if s.wroteHeader {
+		return
+	}
+	s.wroteHeader = true
+	s.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write marks the response as committed and forwards the body
+func (s *responseState) Write(b []byte) (int, error) {
+	s.wroteHeader = true
+	return s.ResponseWriter.Write(b)
+}
+
+// Flush forwards to the underlying ResponseWriter if it supports flushing
+func (s *responseState) Flush() {
+	s.wroteHeader = true
+	http.NewResponseController(s.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (s *responseState) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// responseCommitted reports whether the response for r has already been written
+func responseCommitted(r *http.Request) bool {
+	state, ok := r.Context().Value(responseStateContextKey{}).(*responseState)
+	return ok && state.wroteHeader
+}
@@ -0,0 +1,243 @@
+package framework_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/RottenNinja-Go/framework"
+	"github.com/RottenNinja-Go/framework/handler"
+)
+
+type ListPostsRequest struct {
+	Route struct {
+		ID     string `json:"id" validate:"required"`
+		PostID string `json:"postId"`
+	}
+}
+
+type PostsResponse struct {
+	User string `json:"user"`
+	Post string `json:"post"`
+}
+
+func TestOptionalSegment(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/users/{id}/posts/{postId?}", func(ctx context.Context, req ListPostsRequest) (PostsResponse, error) {
+		return PostsResponse{User: req.Route.ID, Post: req.Route.PostID}, nil
+	}, func(eo handler.EndpointOptions) {})
+
+	tests := []struct {
+		target string
+		body   string
+	}{
+		{"/users/1/posts", `{"user":"1","post":""}`},
+		{"/users/1/posts/9", `{"user":"1","post":"9"}`},
+	}
+	for _, tt := range tests {
+		w := serve(app, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		expectStatus(t, w, http.StatusOK)
+		if got := w.Body.String(); got != tt.body+"\n" {
+			t.Errorf("%s: body = %s, want %s", tt.target, got, tt.body)
+		}
+	}
+
+	routes := app.Routes()
+	if len(routes) != 2 || routes[0].Path != "/users/{id}/posts" || routes[1].Path != "/users/{id}/posts/{postId}" {
+		t.Errorf("Routes() = %+v, want both forms registered", routes)
+	}
+}
+
+func TestURL(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/users/{id}", okHandler, func(eo handler.EndpointOptions) {})
+	handler.GET(app, "/files/{path...}", okHandler, func(eo handler.EndpointOptions) {})
+
+	tests := []struct {
+		pattern string
+		params  map[string]string
+		want    string
+	}{
+		{"/users/{id}", map[string]string{"id": "42"}, "/users/42"},
+		{"/users/{id}", map[string]string{"id": "a b/c"}, "/users/a%20b%2Fc"},
+		{"/files/{path...}", map[string]string{"path": "docs/read me.txt"}, "/files/docs/read%20me.txt"},
+	}
+	for _, tt := range tests {
+		got, err := app.URL(http.MethodGet, tt.pattern, tt.params)
+		if err != nil || got != tt.want {
+			t.Errorf("URL(%s, %v) = %q, %v, want %q", tt.pattern, tt.params, got, err, tt.want)
+		}
+	}
+
+	if _, err := app.URL(http.MethodGet, "/users/{id}", nil); err == nil {
+		t.Error("URL() succeeded without the id parameter")
+	}
+	if _, err := app.URL(http.MethodDelete, "/users/{id}", map[string]string{"id": "42"}); err == nil {
+		t.Error("URL() succeeded for an unregistered endpoint")
+	}
+}
+
+// registrationPanic returns the message register panics with, or "" if it doesn't
+func registrationPanic(register func()) (message string) {
+	defer func() {
+		if p := recover(); p != nil {
+			message = fmt.Sprint(p)
+		}
+	}()
+	register()
+	return ""
+}
+
+type UserIDRequest struct {
+	Route struct {
+		UserID string `json:"id" validate:"required"`
+	}
+}
+
+func TestStrictRegistrationMissingRouteParam(t *testing.T) {
+	getUser := func(ctx context.Context, req UserIDRequest) (UploadResponse, error) {
+		return UploadResponse{}, nil
+	}
+
+	// Without strict registration the endpoint registers and every request fails
+	app := framework.New()
+	handler.GET(app, "/users", getUser, func(eo handler.EndpointOptions) {})
+	expectStatus(t, serve(app, httptest.NewRequest(http.MethodGet, "/users", nil)), http.StatusBadRequest)
+
+	app = framework.New()
+	app.SetStrictRegistration(true)
+	message := registrationPanic(func() {
+		handler.GET(app, "/users", getUser, func(eo handler.EndpointOptions) {})
+	})
+	want := `framework: invalid endpoint GET /users: required route parameter "id" (field Route.UserID) has no {id} in the path`
+	if message != want {
+		t.Errorf("panic = %q, want %q", message, want)
+	}
+
+	if message := registrationPanic(func() {
+		handler.GET(app, "/users/{id}", getUser, func(eo handler.EndpointOptions) {})
+	}); message != "" {
+		t.Errorf("valid endpoint panicked: %s", message)
+	}
+}
+
+type TaggedOptionsRequest struct {
+	Route struct {
+		UserID string `json:"id,omitempty" validate:"required"`
+	}
+	Query struct {
+		Verbose bool `json:"verbose,omitempty"`
+	}
+}
+
+type TaggedOptionsResponse struct {
+	ID      string `json:"id"`
+	Verbose bool   `json:"verbose"`
+}
+
+func TestStrictRegistrationTagOptions(t *testing.T) {
+	app := framework.New()
+	app.SetStrictRegistration(true)
+	if message := registrationPanic(func() {
+		handler.GET(app, "/users/{id}", func(ctx context.Context, req TaggedOptionsRequest) (TaggedOptionsResponse, error) {
+			return TaggedOptionsResponse{ID: req.Route.UserID, Verbose: req.Query.Verbose}, nil
+		}, func(eo handler.EndpointOptions) {})
+	}); message != "" {
+		t.Fatalf("endpoint with json tag options panicked: %s", message)
+	}
+
+	// Parameters bind by the tag name without its options
+	w := serve(app, httptest.NewRequest(http.MethodGet, "/users/42?verbose=true", nil))
+	expectStatus(t, w, http.StatusOK)
+	if want := `{"id":"42","verbose":true}` + "\n"; w.Body.String() != want {
+		t.Errorf("body = %s, want %s", w.Body.String(), want)
+	}
+}
+
+type UntaggedQueryRequest struct {
+	Query struct {
+		IncludeDetails bool
+	}
+}
+
+func TestStrictRegistrationMissingTag(t *testing.T) {
+	getUsers := func(ctx context.Context, req UntaggedQueryRequest) (UploadResponse, error) {
+		return UploadResponse{}, nil
+	}
+
+	app := framework.New()
+	app.SetStrictRegistration(true)
+	message := registrationPanic(func() {
+		handler.GET(app, "/users", getUsers, func(eo handler.EndpointOptions) {})
+	})
+	if !strings.Contains(message, "Query.IncludeDetails") || !strings.Contains(message, "json") {
+		t.Errorf("panic = %q, want the untagged field named", message)
+	}
+
+	// Outside strict mode the field binds by its Go name
+	app = framework.New()
+	if message := registrationPanic(func() {
+		handler.GET(app, "/users", getUsers, func(eo handler.EndpointOptions) {})
+	}); message != "" {
+		t.Errorf("non-strict registration panicked: %s", message)
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/users/{id}", okHandler, func(eo handler.EndpointOptions) {})
+	handler.DELETE(app, "/users/{id}", okHandler, func(eo handler.EndpointOptions) {})
+	handler.POST(app, "/users", okHandler, func(eo handler.EndpointOptions) {})
+
+	tests := []struct {
+		method, target, allow string
+	}{
+		{http.MethodPut, "/users/1", "DELETE, GET, HEAD"},
+		{http.MethodGet, "/users", "POST"},
+	}
+	for _, tt := range tests {
+		w := serve(app, httptest.NewRequest(tt.method, tt.target, nil))
+		expectStatus(t, w, http.StatusMethodNotAllowed)
+		if got := w.Header().Get("Allow"); got != tt.allow {
+			t.Errorf("%s %s: Allow = %q, want %q", tt.method, tt.target, got, tt.allow)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("%s %s: Content-Type = %q, want application/json", tt.method, tt.target, ct)
+		}
+	}
+
+	// Unknown paths are still not found
+	expectStatus(t, serve(app, httptest.NewRequest(http.MethodPut, "/posts/1", nil)), http.StatusNotFound)
+}
+
+func TestNotFound(t *testing.T) {
+	app := framework.New()
+	handler.GET(app, "/users", okHandler, func(eo handler.EndpointOptions) {})
+
+	w := serve(app, httptest.NewRequest(http.MethodGet, "/posts", nil))
+	expectStatus(t, w, http.StatusNotFound)
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	if want := `{"error":"not found"}` + "\n"; w.Body.String() != want {
+		t.Errorf("body = %s, want %s", w.Body.String(), want)
+	}
+
+	app.SetNotFoundHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		fmt.Fprintf(w, "no %s here", r.URL.Path)
+	}))
+	w = serve(app, httptest.NewRequest(http.MethodGet, "/posts", nil))
+	expectStatus(t, w, http.StatusTeapot)
+	if w.Body.String() != "no /posts here" {
+		t.Errorf("body = %q, want the custom handler's", w.Body.String())
+	}
+	// Matched routes don't reach it
+	expectStatus(t, serve(app, httptest.NewRequest(http.MethodGet, "/users", nil)), http.StatusOK)
+
+	app.SetNotFoundHandler(nil)
+	expectStatus(t, serve(app, httptest.NewRequest(http.MethodGet, "/posts", nil)), http.StatusNotFound)
+}